@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"gitee.com/NextEraAbyss/gin-template/config"
+	"gitee.com/NextEraAbyss/gin-template/internal/mysql"
+	"gitee.com/NextEraAbyss/gin-template/internal/redact"
+	"gitee.com/NextEraAbyss/gin-template/internal/seed"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+)
+
+// 数据填充命令.
+//
+// 用法:
+//
+//	go run ./cmd/seed                              # 执行当前环境允许的所有填充器
+//	go run ./cmd/seed -only admin                  # 只执行指定的填充器，多个用逗号分隔
+//	go run ./cmd/seed -fixtures testdata/fixtures  # 加载数据文件（YAML/JSON）
+//	go run ./cmd/seed -list                        # 列出所有填充器
+//
+// 生产环境默认拒绝执行，需要显式指定 -force.
+func main() {
+	only := flag.String("only", "", "要执行的填充器名称，多个用逗号分隔，默认执行当前环境允许的全部填充器")
+	fixtures := flag.String("fixtures", "", "要加载的数据文件或目录，多个用逗号分隔；指定后不执行填充器")
+	force := flag.Bool("force", false, "允许在生产环境执行")
+	list := flag.Bool("list", false, "列出所有已注册的填充器")
+	flag.Parse()
+
+	// 加载配置.
+	cfg := config.LoadConfig()
+
+	// 初始化日志.
+	logConfig, err := utils.NewLogConfig(cfg)
+	utils.InitLogger(logConfig)
+	defer utils.CloseLogger()
+	if err != nil {
+		utils.Errorf("部分日志输出初始化失败: %v", err)
+	}
+
+	// 初始化敏感数据脱敏，在内置名单的基础上追加配置的名单.
+	redactor, err := redact.NewFromConfig(cfg)
+	if err != nil {
+		utils.Fatalf("初始化敏感数据脱敏失败: %v", err)
+	}
+	redact.SetDefault(redactor)
+
+	registry := seed.DefaultRegistry(cfg)
+	if *list {
+		for _, name := range registry.Names() {
+			fmt.Println(name)
+		}
+		return
+	}
+
+	if cfg.Env == config.EnvProduction && !*force {
+		utils.Fatalf("当前为生产环境，拒绝执行数据填充；如确需执行请添加 -force 参数")
+	}
+
+	// 初始化数据库.
+	db := mysql.InitDB(cfg)
+	defer func() {
+		if err := mysql.CloseDB(); err != nil {
+			utils.Errorf("关闭数据库连接失败: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	if err := mysql.Migrate(ctx, db); err != nil {
+		utils.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	if *fixtures != "" {
+		if err := seed.LoadFixtures(ctx, db, splitList(*fixtures)...); err != nil {
+			utils.Fatalf("加载数据文件失败: %v", err)
+		}
+		utils.Infof("数据文件加载完成")
+		return
+	}
+
+	if err := registry.Run(ctx, db, cfg.Env, splitList(*only)); err != nil {
+		utils.Fatalf("%v", err)
+	}
+	utils.Infof("数据填充完成")
+}
+
+// splitList 解析逗号分隔的参数.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
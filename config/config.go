@@ -5,10 +5,18 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
+// 运行环境名称.
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvProduction  = "production"
+)
+
 // Config 应用程序配置.
 type Config struct {
 	Env    string
@@ -29,11 +37,162 @@ type Config struct {
 		Password string
 		DB       int
 	}
+	// 日志配置
+	Log struct {
+		Level  string // 日志级别：debug、info、warn、error
+		Format string // 输出格式：json 或 console
+		// 输出目标：console、file、syslog、collector，可同时配置多个
+		Sinks      []string
+		BufferSize int // 异步写入时每个输出目标缓冲的日志条数，0 表示同步写入
+
+		// 各输出目标的最低级别，可按级别将日志路由到不同的目标
+		ConsoleLevel   string
+		FileLevel      string
+		SyslogLevel    string
+		CollectorLevel string
+
+		FilePath       string // 日志文件路径
+		ErrorFilePath  string // 错误日志文件路径，只写入 error 及以上级别，为空时不单独输出
+		FileMaxSizeMB  int    // 单个日志文件的最大大小（MB）
+		FileMaxBackups int    // 保留的历史日志文件数量
+		FileMaxAgeDays int    // 历史日志文件保留天数
+		FileDaily      bool   // 是否每天零点切分日志文件
+		FileCompress   bool   // 是否压缩历史日志文件
+
+		SyslogNetwork string // 系统日志服务协议，为空时使用本机的系统日志服务
+		SyslogAddr    string // 系统日志服务地址
+		SyslogTag     string // 系统日志标识
+
+		CollectorNetwork string // 日志采集器协议：tcp 或 udp
+		CollectorAddr    string // 日志采集器地址，如 127.0.0.1:5170
+	}
+	// 敏感数据脱敏配置，在内置名单的基础上追加
+	Redact struct {
+		Fields      []string // 敏感字段名（JSON键、表单字段、数据库列）
+		Headers     []string // 敏感请求头
+		QueryParams []string // 敏感查询参数
+		Patterns    []string // 敏感内容正则表达式
+	}
 	// 添加JWT配置
 	JWT struct {
 		Secret          string
 		ExpirationHours int
 	}
+	// 缓存配置
+	Cache struct {
+		Namespace              string // Redis缓存键命名空间前缀，清空缓存时只删除该前缀下的键
+		L1TTLSeconds           int    // 本地缓存有效期（秒），应短于Redis缓存有效期
+		L1MaxEntries           int    // 本地缓存最大条目数
+		L1MaxMB                int    // 本地缓存最大占用内存（MB）
+		InvalidationChannel    string // 缓存失效广播的Redis频道
+		UserTTLSeconds         int    // 用户数据缓存有效期（秒）
+		UserNegativeTTLSeconds int    // 用户不存在结果的缓存有效期（秒）
+		Codec                  string // 缓存编解码器：json、msgpack、gob
+		CompressThreshold      int    // 超过该字节数的缓存数据使用zstd压缩，0表示不压缩
+		HTTPMaxAgeSeconds      int    // 公开接口响应的客户端缓存有效期（秒），0表示每次使用ETag重新验证
+		HTTPServerTTLSeconds   int    // 公开接口响应的服务端缓存有效期（秒），0表示不在服务端缓存
+	}
+	// 跨域配置
+	CORS struct {
+		AllowOrigins      []string // 允许的来源，支持精确匹配、通配子域名和 /正则/
+		AdminAllowOrigins []string // 管理接口允许的来源，为空时与 AllowOrigins 相同
+		AllowMethods      []string // 允许的请求方法，为空时使用默认值
+		AllowHeaders      []string // 允许的请求头，为空时使用默认值
+		ExposeHeaders     []string // 允许前端读取的响应头，为空时使用默认值
+		AllowCredentials  bool     // 是否允许携带凭证
+		MaxAgeSeconds     int      // 预检请求结果的缓存时间（秒）
+	}
+	// 限流配置，格式为 "次数/周期"，如 "1000/1m"
+	RateLimit struct {
+		Global string // 全局按IP限流策略
+		User   string // 需认证的用户接口按用户限流策略
+		Admin  string // 管理接口按用户限流策略
+	}
+	// 管理接口配置
+	Admin struct {
+		Enabled bool   // 是否启用管理接口（pprof、expvar、路由列表和运行时开关）
+		Addr    string // 监听地址，只能为本机或内网地址
+	}
+	// 健康检查配置
+	Health struct {
+		CheckTimeoutSeconds  int // 单项检查超时时间（秒）
+		CheckCacheSeconds    int // 检查结果缓存时间（秒）
+		ShutdownDelaySeconds int // 关闭时就绪探针失败后等待多久再关闭监听（秒），应大于负载均衡的探测间隔
+	}
+	// 指标配置
+	Metrics struct {
+		Enabled bool   // 是否启用Prometheus指标
+		Path    string // 指标接口路径
+		Addr    string // 指标接口的独立监听地址，默认只监听本机，为空时与业务接口使用同一端口
+	}
+	// 链路追踪配置
+	Tracing struct {
+		Exporter     string  // 导出方式：none、otlp、stdout、file
+		ServiceName  string  // 服务名称
+		OTLPEndpoint string  // OTLP接收地址，为空时使用 OTEL_EXPORTER_OTLP_ENDPOINT
+		OTLPProtocol string  // OTLP协议：grpc 或 http
+		OTLPInsecure bool    // 是否使用不加密的连接
+		FilePath     string  // file 导出方式写入的文件路径
+		SampleRatio  float64 // 根span的采样比例（0-1）
+	}
+	// 错误上报配置
+	ErrorReport struct {
+		Transports    []string // 发送方式：sentry、file，可同时配置多个，为空时不上报
+		SentryDSN     string   // Sentry兼容服务的DSN
+		FilePath      string   // file 发送方式写入的文件路径
+		Release       string   // 版本号
+		SampleRate    float64  // 重复事件的采样比例（0-1），每个分组首次出现的事件总是上报
+		GroupLimit    int      // 每个分组在时间窗口内最多上报的事件数，0 表示不限制
+		GlobalLimit   int      // 所有分组在时间窗口内最多上报的事件数，0 表示不限制
+		WindowSeconds int      // 限流时间窗口（秒）
+		QueueSize     int      // 待发送事件的缓冲数量
+	}
+	// 响应压缩配置
+	Compress struct {
+		Enabled      bool     // 是否启用响应压缩
+		Encodings    []string // 支持的压缩算法，按优先顺序排列，为空时使用默认值
+		MinSize      int      // 响应达到该字节数才压缩
+		ContentTypes []string // 允许压缩的内容类型，为空时使用默认值
+	}
+	// 请求限制配置
+	Request struct {
+		MaxBodyKB          int // 请求体最大大小（KB）
+		TimeoutSeconds     int // 请求处理超时时间（秒）
+		UploadMaxBodyMB    int // 文件上传接口的请求体最大大小（MB）
+		LongTimeoutSeconds int // 导入导出等耗时接口的处理超时时间（秒）
+	}
+	// 幂等请求配置
+	Idempotency struct {
+		TTLHours    int // 响应保存时间（小时）
+		LockSeconds int // 处理中记录的有效期（秒），不足接口处理超时时间时自动延长
+	}
+	// 审计日志配置
+	Audit struct {
+		RetentionDays int // 审计日志保留天数，0表示永久保留
+	}
+	// 数据填充配置
+	Seed struct {
+		AdminUsername string // 管理员用户名
+		AdminEmail    string // 管理员邮箱
+		AdminPassword string // 管理员初始密码
+		DemoUsers     int    // 演示用户数量
+	}
+	// 发件箱配置
+	Outbox struct {
+		PollIntervalSeconds int      // 轮询间隔（秒）
+		BatchSize           int      // 每次投递的事件数
+		MaxAttempts         int      // 最大投递次数，超过后进入死信
+		RedisStream         string   // 事件写入的Redis Stream名称，为空表示不启用
+		WebhookURLs         []string // 事件推送的Webhook地址
+		WebhookSecret       string   // Webhook签名密钥
+	}
+	// 用户隐私数据配置
+	Privacy struct {
+		ExportTTLHours        int // 数据导出文件有效期（小时）
+		ErasureGraceDays      int // 数据删除宽限期（天），0表示立即删除
+		WorkerIntervalSeconds int // 后台处理间隔（秒）
+		ClaimTimeoutMinutes   int // 请求认领后的处理时限（分钟），超时后重新放回待处理
+	}
 }
 
 // LoadConfig 加载配置
@@ -49,7 +208,42 @@ func LoadConfig() *Config {
 	config := &Config{}
 
 	// 设置环境
-	config.Env = getEnv("ENV", "development")
+	config.Env = getEnv("ENV", EnvDevelopment)
+
+	// 日志配置，生产环境默认输出JSON便于采集
+	config.Log.Level = getEnv("LOG_LEVEL", "info")
+	defaultLogFormat := "console"
+	if config.Env == EnvProduction {
+		defaultLogFormat = "json"
+	}
+	config.Log.Format = getEnv("LOG_FORMAT", defaultLogFormat)
+	config.Log.Sinks = getEnvList("LOG_SINKS")
+	if len(config.Log.Sinks) == 0 {
+		config.Log.Sinks = []string{"console"}
+	}
+	config.Log.BufferSize = getEnvInt("LOG_BUFFER_SIZE", 8192)
+	config.Log.ConsoleLevel = getEnv("LOG_CONSOLE_LEVEL", "debug")
+	config.Log.FileLevel = getEnv("LOG_FILE_LEVEL", "debug")
+	config.Log.SyslogLevel = getEnv("LOG_SYSLOG_LEVEL", "warn")
+	config.Log.CollectorLevel = getEnv("LOG_COLLECTOR_LEVEL", "debug")
+	config.Log.FilePath = getEnv("LOG_FILE_PATH", "logs/app.log")
+	config.Log.ErrorFilePath = getEnv("LOG_ERROR_FILE_PATH", "logs/error.log")
+	config.Log.FileMaxSizeMB = getEnvInt("LOG_FILE_MAX_SIZE_MB", 100)
+	config.Log.FileMaxBackups = getEnvInt("LOG_FILE_MAX_BACKUPS", 30)
+	config.Log.FileMaxAgeDays = getEnvInt("LOG_FILE_MAX_AGE_DAYS", 30)
+	config.Log.FileDaily = getEnvBool("LOG_FILE_DAILY", true)
+	config.Log.FileCompress = getEnvBool("LOG_FILE_COMPRESS", true)
+	config.Log.SyslogNetwork = getEnv("LOG_SYSLOG_NETWORK", "")
+	config.Log.SyslogAddr = getEnv("LOG_SYSLOG_ADDR", "")
+	config.Log.SyslogTag = getEnv("LOG_SYSLOG_TAG", "gin-template")
+	config.Log.CollectorNetwork = getEnv("LOG_COLLECTOR_NETWORK", "udp")
+	config.Log.CollectorAddr = getEnv("LOG_COLLECTOR_ADDR", "")
+
+	// 脱敏配置，正则表达式中可能包含逗号，以空白字符分隔
+	config.Redact.Fields = getEnvList("REDACT_FIELDS")
+	config.Redact.Headers = getEnvList("REDACT_HEADERS")
+	config.Redact.QueryParams = getEnvList("REDACT_QUERY_PARAMS")
+	config.Redact.Patterns = strings.Fields(os.Getenv("REDACT_PATTERNS"))
 
 	// 服务器配置
 	config.Server.Host = getEnv("SERVER_HOST", "0.0.0.0")
@@ -77,6 +271,106 @@ func LoadConfig() *Config {
 	}
 	config.JWT.ExpirationHours = expirationHours
 
+	// 缓存配置
+	config.Cache.Namespace = getEnv("CACHE_NAMESPACE", "gin-template:")
+	config.Cache.L1TTLSeconds = getEnvInt("CACHE_L1_TTL_SECONDS", 30)
+	config.Cache.L1MaxEntries = getEnvInt("CACHE_L1_MAX_ENTRIES", 100000)
+	config.Cache.L1MaxMB = getEnvInt("CACHE_L1_MAX_MB", 64)
+	config.Cache.InvalidationChannel = getEnv("CACHE_INVALIDATION_CHANNEL", "cache:invalidate")
+	config.Cache.UserTTLSeconds = getEnvInt("CACHE_USER_TTL_SECONDS", 300)
+	config.Cache.UserNegativeTTLSeconds = getEnvInt("CACHE_USER_NEGATIVE_TTL_SECONDS", 30)
+	config.Cache.Codec = getEnv("CACHE_CODEC", "json")
+	config.Cache.CompressThreshold = getEnvInt("CACHE_COMPRESS_THRESHOLD", 1024)
+	config.Cache.HTTPMaxAgeSeconds = getEnvInt("CACHE_HTTP_MAX_AGE_SECONDS", 0)
+	config.Cache.HTTPServerTTLSeconds = getEnvInt("CACHE_HTTP_SERVER_TTL_SECONDS", 60)
+
+	// 跨域配置
+	config.CORS.AllowOrigins = getEnvList("CORS_ALLOW_ORIGINS")
+	config.CORS.AdminAllowOrigins = getEnvList("CORS_ADMIN_ALLOW_ORIGINS")
+	config.CORS.AllowMethods = getEnvList("CORS_ALLOW_METHODS")
+	config.CORS.AllowHeaders = getEnvList("CORS_ALLOW_HEADERS")
+	config.CORS.ExposeHeaders = getEnvList("CORS_EXPOSE_HEADERS")
+	config.CORS.AllowCredentials = getEnvBool("CORS_ALLOW_CREDENTIALS", true)
+	config.CORS.MaxAgeSeconds = getEnvInt("CORS_MAX_AGE_SECONDS", 43200)
+
+	// 限流配置
+	config.RateLimit.Global = getEnv("RATE_LIMIT_GLOBAL", "1000/1m")
+	config.RateLimit.User = getEnv("RATE_LIMIT_USER", "300/1m")
+	config.RateLimit.Admin = getEnv("RATE_LIMIT_ADMIN", "120/1m")
+
+	// 管理接口配置
+	config.Admin.Enabled = getEnvBool("ADMIN_ENABLED", false)
+	config.Admin.Addr = getEnv("ADMIN_ADDR", "127.0.0.1:6060")
+
+	// 健康检查配置
+	config.Health.CheckTimeoutSeconds = getEnvInt("HEALTH_CHECK_TIMEOUT_SECONDS", 2)
+	config.Health.CheckCacheSeconds = getEnvInt("HEALTH_CHECK_CACHE_SECONDS", 2)
+	config.Health.ShutdownDelaySeconds = getEnvInt("HEALTH_SHUTDOWN_DELAY_SECONDS", 5)
+
+	// 指标配置
+	config.Metrics.Enabled = getEnvBool("METRICS_ENABLED", true)
+	config.Metrics.Path = getEnv("METRICS_PATH", "/metrics")
+	config.Metrics.Addr = getEnv("METRICS_ADDR", "127.0.0.1:9100")
+
+	// 链路追踪配置
+	config.Tracing.Exporter = getEnv("TRACING_EXPORTER", "none")
+	config.Tracing.ServiceName = getEnv("TRACING_SERVICE_NAME", "gin-template")
+	config.Tracing.OTLPEndpoint = getEnv("TRACING_OTLP_ENDPOINT", "")
+	config.Tracing.OTLPProtocol = getEnv("TRACING_OTLP_PROTOCOL", "grpc")
+	config.Tracing.OTLPInsecure = getEnvBool("TRACING_OTLP_INSECURE", false)
+	config.Tracing.FilePath = getEnv("TRACING_FILE_PATH", "logs/traces.jsonl")
+	config.Tracing.SampleRatio = getEnvFloat("TRACING_SAMPLE_RATIO", 1)
+
+	// 错误上报配置
+	config.ErrorReport.Transports = getEnvList("ERROR_REPORT_TRANSPORTS")
+	config.ErrorReport.SentryDSN = getEnv("ERROR_REPORT_SENTRY_DSN", "")
+	config.ErrorReport.FilePath = getEnv("ERROR_REPORT_FILE_PATH", "logs/errors.jsonl")
+	config.ErrorReport.Release = getEnv("ERROR_REPORT_RELEASE", "")
+	config.ErrorReport.SampleRate = getEnvFloat("ERROR_REPORT_SAMPLE_RATE", 1)
+	config.ErrorReport.GroupLimit = getEnvInt("ERROR_REPORT_GROUP_LIMIT", 10)
+	config.ErrorReport.GlobalLimit = getEnvInt("ERROR_REPORT_GLOBAL_LIMIT", 100)
+	config.ErrorReport.WindowSeconds = getEnvInt("ERROR_REPORT_WINDOW_SECONDS", 60)
+	config.ErrorReport.QueueSize = getEnvInt("ERROR_REPORT_QUEUE_SIZE", 256)
+
+	// 响应压缩配置
+	config.Compress.Enabled = getEnvBool("COMPRESS_ENABLED", true)
+	config.Compress.Encodings = getEnvList("COMPRESS_ENCODINGS")
+	config.Compress.MinSize = getEnvInt("COMPRESS_MIN_SIZE", 1024)
+	config.Compress.ContentTypes = getEnvList("COMPRESS_CONTENT_TYPES")
+
+	// 请求限制配置
+	config.Request.MaxBodyKB = getEnvInt("REQUEST_MAX_BODY_KB", 1024)
+	config.Request.TimeoutSeconds = getEnvInt("REQUEST_TIMEOUT_SECONDS", 10)
+	config.Request.UploadMaxBodyMB = getEnvInt("REQUEST_UPLOAD_MAX_BODY_MB", 32)
+	config.Request.LongTimeoutSeconds = getEnvInt("REQUEST_LONG_TIMEOUT_SECONDS", 120)
+
+	// 幂等请求配置
+	config.Idempotency.TTLHours = getEnvInt("IDEMPOTENCY_TTL_HOURS", 24)
+	config.Idempotency.LockSeconds = getEnvInt("IDEMPOTENCY_LOCK_SECONDS", 60)
+
+	// 审计日志配置
+	config.Audit.RetentionDays = getEnvInt("AUDIT_RETENTION_DAYS", 180) // 默认保留180天
+
+	// 数据填充配置
+	config.Seed.AdminUsername = getEnv("SEED_ADMIN_USERNAME", "admin")
+	config.Seed.AdminEmail = getEnv("SEED_ADMIN_EMAIL", "admin@example.com")
+	config.Seed.AdminPassword = getEnv("SEED_ADMIN_PASSWORD", "")
+	config.Seed.DemoUsers = getEnvInt("SEED_DEMO_USERS", 20)
+
+	// 发件箱配置
+	config.Outbox.PollIntervalSeconds = getEnvInt("OUTBOX_POLL_INTERVAL_SECONDS", 1)
+	config.Outbox.BatchSize = getEnvInt("OUTBOX_BATCH_SIZE", 100)
+	config.Outbox.MaxAttempts = getEnvInt("OUTBOX_MAX_ATTEMPTS", 10)
+	config.Outbox.RedisStream = getEnv("OUTBOX_REDIS_STREAM", "")
+	config.Outbox.WebhookURLs = getEnvList("OUTBOX_WEBHOOK_URLS")
+	config.Outbox.WebhookSecret = getEnv("OUTBOX_WEBHOOK_SECRET", "")
+
+	// 用户隐私数据配置
+	config.Privacy.ExportTTLHours = getEnvInt("PRIVACY_EXPORT_TTL_HOURS", 24)
+	config.Privacy.ErasureGraceDays = getEnvInt("PRIVACY_ERASURE_GRACE_DAYS", 30)
+	config.Privacy.WorkerIntervalSeconds = getEnvInt("PRIVACY_WORKER_INTERVAL_SECONDS", 30)
+	config.Privacy.ClaimTimeoutMinutes = getEnvInt("PRIVACY_CLAIM_TIMEOUT_MINUTES", 30)
+
 	// 打印当前使用的配置信息
 	printConfig(config)
 
@@ -92,9 +386,47 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// getEnvInt 获取整数类型的环境变量，不存在、无法解析或为负数时返回默认值
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvBool 获取布尔类型的环境变量，不存在或无法解析时返回默认值
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvFloat 获取浮点数类型的环境变量，不存在、无法解析或为负数时返回默认值
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(getEnv(key, ""), 64)
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvList 获取逗号分隔的环境变量列表，忽略空项
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // printConfig 打印配置信息（隐藏敏感信息）
 func printConfig(config *Config) {
-	if config.Env != "production" {
+	if config.Env != EnvProduction {
 		fmt.Println("=== 应用配置信息 ===")
 		fmt.Printf("环境: %s\n", config.Env)
 		fmt.Printf("服务器: %s:%d\n", config.Server.Host, config.Server.Port)
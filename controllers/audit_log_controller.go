@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"gitee.com/NextEraAbyss/gin-template/services"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"gitee.com/NextEraAbyss/gin-template/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogController 审计日志控制器
+type AuditLogController struct {
+	auditLogService services.AuditLogService
+}
+
+// NewAuditLogController 创建审计日志控制器
+func NewAuditLogController(auditLogService services.AuditLogService) *AuditLogController {
+	return &AuditLogController{
+		auditLogService: auditLogService,
+	}
+}
+
+// List 获取审计日志列表
+// @Summary      审计日志查询
+// @Description  按实体、操作人和时间范围查询数据变更审计日志，结果按时间倒序排列
+// @Tags         系统管理
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        page         query    int     false  "页码，从1开始计数"    default(1)
+// @Param        page_size    query    int     false  "每页记录数，默认10条"  default(10)
+// @Param        entity_type  query    string  false  "实体类型（表名），如users"
+// @Param        entity_id    query    string  false  "实体ID"
+// @Param        actor_id     query    int     false  "操作人用户ID"
+// @Param        action       query    string  false  "操作类型: create、update或delete"
+// @Param        start_time   query    string  false  "开始时间，格式: 2006-01-02 15:04:05"
+// @Param        end_time     query    string  false  "结束时间，格式: 2006-01-02 15:04:05"
+// @Success      200          {object}  validation.AuditLogListResponseDTO  "审计日志列表数据，包含总数和分页记录"
+// @Router       /api/v1/admin/audit-logs [get]
+func (ctrl *AuditLogController) List(c *gin.Context) {
+	// 验证查询参数
+	var queryDTO validation.AuditLogQueryDTO
+	if !utils.ValidateQuery(c, &queryDTO) {
+		return
+	}
+
+	// 设置默认值
+	if queryDTO.Page <= 0 {
+		queryDTO.Page = 1
+	}
+	if queryDTO.PageSize <= 0 {
+		queryDTO.PageSize = 10
+	}
+
+	// 获取审计日志列表
+	logs, total, err := ctrl.auditLogService.List(c.Request.Context(), &queryDTO)
+	if err != nil {
+		utils.LogAndResponseError(c, utils.CodeInternalError, err)
+		return
+	}
+
+	// 转换为DTO响应
+	items := make([]validation.AuditLogResponseDTO, 0, len(logs))
+	for _, log := range logs {
+		items = append(items, validation.FromAuditLog(log))
+	}
+
+	// 计算总页数
+	pages := int(total) / queryDTO.PageSize
+	if int(total)%queryDTO.PageSize > 0 {
+		pages++
+	}
+
+	// 返回结果
+	utils.ResponseSuccess(c, validation.AuditLogListResponseDTO{
+		Total:    total,
+		Items:    items,
+		Page:     queryDTO.Page,
+		PageSize: queryDTO.PageSize,
+		Pages:    pages,
+	})
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"net/http"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+// HealthController 健康检查控制器
+type HealthController struct {
+	registry *health.Registry
+}
+
+// NewHealthController 创建健康检查控制器
+func NewHealthController(registry *health.Registry) *HealthController {
+	return &HealthController{
+		registry: registry,
+	}
+}
+
+// Livez 存活探针
+// @Summary      存活探针
+// @Description  进程是否存活，不检查外部依赖
+// @Tags         健康检查
+// @Produce      json
+// @Success      200  {object}  health.Report  "存活"
+// @Failure      503  {object}  health.Report  "不存活"
+// @Router       /livez [get]
+func (ctrl *HealthController) Livez(c *gin.Context) {
+	ctrl.respond(c, health.Liveness)
+}
+
+// Readyz 就绪探针
+// @Summary      就绪探针
+// @Description  是否可以接收流量，检查MySQL、Redis和数据库迁移，服务关闭过程中返回503
+// @Tags         健康检查
+// @Produce      json
+// @Success      200  {object}  health.Report  "就绪"
+// @Failure      503  {object}  health.Report  "未就绪，checks 中包含各项检查的失败原因"
+// @Router       /readyz [get]
+func (ctrl *HealthController) Readyz(c *gin.Context) {
+	ctrl.respond(c, health.Readiness)
+}
+
+// Startupz 启动探针
+// @Summary      启动探针
+// @Description  是否已完成启动，检查MySQL连接和数据库迁移，通过一次后不再检查
+// @Tags         健康检查
+// @Produce      json
+// @Success      200  {object}  health.Report  "已启动"
+// @Failure      503  {object}  health.Report  "启动中"
+// @Router       /startupz [get]
+func (ctrl *HealthController) Startupz(c *gin.Context) {
+	ctrl.respond(c, health.Startup)
+}
+
+// respond 执行探针并返回结果，健康时返回200，否则返回503
+func (ctrl *HealthController) respond(c *gin.Context, probe health.Probe) {
+	report := ctrl.registry.Run(c.Request.Context(), probe)
+
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
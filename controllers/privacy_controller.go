@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"gitee.com/NextEraAbyss/gin-template/services"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"gitee.com/NextEraAbyss/gin-template/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// PrivacyController 用户隐私数据控制器
+type PrivacyController struct {
+	privacyService services.PrivacyService
+}
+
+// NewPrivacyController 创建用户隐私数据控制器
+func NewPrivacyController(privacyService services.PrivacyService) *PrivacyController {
+	return &PrivacyController{
+		privacyService: privacyService,
+	}
+}
+
+// Export 申请导出个人数据
+// @Summary      申请导出个人数据
+// @Description  异步生成当前登录用户全部个人数据的导出文件。已有处理中或未过期的导出时直接返回该请求，status为completed时可通过download_url下载
+// @Tags         用户隐私
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        format  query     string  false  "导出格式: zip(每个模块一个JSON文件)或json(单个JSON文件)"  default(zip)
+// @Success      200     {object}  validation.PrivacyRequestResponseDTO  "导出请求"
+// @Router       /api/v1/users/me/export [get]
+func (ctrl *PrivacyController) Export(c *gin.Context) {
+	// 验证查询参数
+	var queryDTO validation.PrivacyExportQueryDTO
+	if !utils.ValidateQuery(c, &queryDTO) {
+		return
+	}
+
+	userID, ok := utils.UserIDFromContext(c.Request.Context())
+	if !ok {
+		utils.ResponseError(c, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	req, err := ctrl.privacyService.RequestExport(c.Request.Context(), userID, queryDTO.Format)
+	if err != nil {
+		utils.LogAndResponseError(c, utils.CodeInternalError, err)
+		return
+	}
+
+	utils.ResponseSuccess(c, validation.FromPrivacyRequest(*req))
+}
+
+// DownloadExport 下载个人数据导出文件
+// @Summary      下载个人数据导出文件
+// @Description  下载当前登录用户最近一次生成且未过期的数据导出文件
+// @Tags         用户隐私
+// @Produce      application/zip
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {file}    file            "导出文件"
+// @Failure      404  {object}  utils.Response  "导出文件尚未生成或已过期"
+// @Router       /api/v1/users/me/export/download [get]
+func (ctrl *PrivacyController) DownloadExport(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c.Request.Context())
+	if !ok {
+		utils.ResponseError(c, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	export, err := ctrl.privacyService.GetExport(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrPrivacyExportNotReady) {
+			utils.ResponseError(c, utils.CodeNotFound, err.Error())
+			return
+		}
+		utils.LogAndResponseError(c, utils.CodeInternalError, err)
+		return
+	}
+
+	contentType := "application/zip"
+	if filepath.Ext(export.FileName) == ".json" {
+		contentType = "application/json"
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName))
+	c.Data(http.StatusOK, contentType, export.Content)
+}
+
+// Erase 申请删除个人数据
+// @Summary      申请删除个人数据
+// @Description  为当前登录用户创建数据删除请求，宽限期结束后匿名化用户资料并删除各模块中的个人数据，宽限期内可以取消
+// @Tags         用户隐私
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  validation.PrivacyRequestResponseDTO  "删除请求，scheduled_at为宽限期结束时间"
+// @Router       /api/v1/users/me/erase [post]
+func (ctrl *PrivacyController) Erase(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c.Request.Context())
+	if !ok {
+		utils.ResponseError(c, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	req, err := ctrl.privacyService.RequestErasure(c.Request.Context(), userID)
+	if err != nil {
+		utils.LogAndResponseError(c, utils.CodeInternalError, err)
+		return
+	}
+
+	utils.ResponseSuccess(c, validation.FromPrivacyRequest(*req))
+}
+
+// CancelErase 取消删除个人数据
+// @Summary      取消删除个人数据
+// @Description  在宽限期内取消当前登录用户的数据删除请求
+// @Tags         用户隐私
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  validation.PrivacyRequestResponseDTO  "已取消的删除请求"
+// @Failure      404  {object}  utils.Response                        "没有可取消的删除请求"
+// @Router       /api/v1/users/me/erase [delete]
+func (ctrl *PrivacyController) CancelErase(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c.Request.Context())
+	if !ok {
+		utils.ResponseError(c, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	req, err := ctrl.privacyService.CancelErasure(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrPrivacyErasureNotFound) {
+			utils.ResponseError(c, utils.CodeNotFound, err.Error())
+			return
+		}
+		utils.LogAndResponseError(c, utils.CodeInternalError, err)
+		return
+	}
+
+	utils.ResponseSuccess(c, validation.FromPrivacyRequest(*req))
+}
@@ -1,7 +1,14 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"gitee.com/NextEraAbyss/gin-template/services"
 	"gitee.com/NextEraAbyss/gin-template/utils"
@@ -53,7 +60,7 @@ func (ctrl *UserController) List(c *gin.Context) {
 	// 获取用户列表
 	users, total, err := ctrl.userService.List(c.Request.Context(), &queryDTO)
 	if err != nil {
-		utils.ResponseError(c, utils.CodeInternalError, err.Error())
+		responseUserError(c, err)
 		return
 	}
 
@@ -146,7 +153,7 @@ func (ctrl *UserController) Update(c *gin.Context) {
 
 	// 保存更新
 	if err := ctrl.userService.Update(c.Request.Context(), user); err != nil {
-		utils.ResponseError(c, utils.CodeInternalError, err.Error())
+		responseUserError(c, err)
 		return
 	}
 
@@ -174,7 +181,7 @@ func (ctrl *UserController) Delete(c *gin.Context) {
 
 	// 删除用户
 	if err := ctrl.userService.Delete(c.Request.Context(), uint(id)); err != nil {
-		utils.ResponseError(c, utils.CodeInternalError, err.Error())
+		responseUserError(c, err)
 		return
 	}
 
@@ -209,10 +216,149 @@ func (ctrl *UserController) ChangePassword(c *gin.Context) {
 	// 修改密码
 	if err := ctrl.userService.ChangePassword(c.Request.Context(), userID.(uint),
 		passwordDTO.OldPassword, passwordDTO.NewPassword); err != nil {
-		utils.ResponseError(c, utils.CodeInternalError, err.Error())
+		responseUserError(c, err)
 		return
 	}
 
 	// 返回成功响应
 	utils.ResponseSuccess(c, nil)
 }
+
+// Import 批量导入用户
+// @Summary      批量导入用户
+// @Description  以multipart/form-data流式上传CSV或NDJSON文件（字段名为file）批量导入用户，每条记录按创建用户的规则校验，用户名已存在时更新该用户的邮箱、昵称和状态，密码仅在update_password为true时更新；邮箱已被其他用户使用的记录导入失败。CSV首行为表头，列名为username、password、email、nickname、status
+// @Tags         用户管理
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     Bearer
+// @Param        file     formData  file    true   "CSV或NDJSON文件"
+// @Param        format   query     string  false  "文件格式: csv或ndjson，默认根据文件扩展名判断"
+// @Param        dry_run  query     bool    false  "仅校验不写入"  default(false)
+// @Param        update_password  query  bool  false  "更新已有用户的密码"  default(false)
+// @Success      200      {object}  validation.UserImportResultDTO  "导入结果，包含失败记录明细"
+// @Router       /api/v1/admin/users/import [post]
+func (ctrl *UserController) Import(c *gin.Context) {
+	// 验证查询参数
+	var queryDTO validation.UserImportQueryDTO
+	if !utils.ValidateQuery(c, &queryDTO) {
+		return
+	}
+
+	// 使用流式读取，避免将整个文件加载到内存或临时文件
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		utils.ResponseError(c, utils.CodeInvalidParams, "请使用multipart/form-data上传文件")
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			utils.ResponseError(c, utils.CodeInvalidParams, "读取上传文件失败")
+			return
+		}
+
+		if part.FormName() != "file" {
+			_ = part.Close()
+			continue
+		}
+
+		format := queryDTO.Format
+		if format == "" {
+			format = transferFormatFromFilename(part.FileName())
+		}
+		if format == "" {
+			utils.ResponseError(c, utils.CodeInvalidParams, "无法识别文件格式，请通过format参数指定csv或ndjson")
+			return
+		}
+
+		result, err := ctrl.userService.Import(c.Request.Context(), part, format, &queryDTO)
+		_ = part.Close()
+		if err != nil {
+			// 只有文件本身的问题返回参数错误，数据库等内部错误按服务端错误处理
+			if errors.Is(err, services.ErrInvalidImportFile) || errors.Is(err, services.ErrUnsupportedFormat) {
+				utils.LogAndResponseError(c, utils.CodeInvalidParams, err)
+				return
+			}
+			utils.LogAndResponseError(c, utils.CodeInternalError, err)
+			return
+		}
+
+		utils.ResponseSuccess(c, result)
+		return
+	}
+
+	utils.ResponseError(c, utils.CodeInvalidParams, "缺少上传文件")
+}
+
+// Export 导出用户
+// @Summary      导出用户
+// @Description  按条件以CSV或NDJSON格式流式导出用户，不包含密码
+// @Tags         用户管理
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Security     Bearer
+// @Param        format   query    string  false  "文件格式: csv或ndjson"  default(csv)
+// @Param        keyword  query    string  false  "搜索关键词，支持用户名、邮箱和昵称模糊搜索"
+// @Param        status   query    int     false  "用户状态"
+// @Success      200      {file}   file    "用户数据文件"
+// @Router       /api/v1/admin/users/export [get]
+func (ctrl *UserController) Export(c *gin.Context) {
+	// 验证查询参数
+	var queryDTO validation.UserExportQueryDTO
+	if !utils.ValidateQuery(c, &queryDTO) {
+		return
+	}
+
+	if queryDTO.Format == "" {
+		queryDTO.Format = validation.TransferFormatCSV
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if queryDTO.Format == validation.TransferFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+
+	filename := fmt.Sprintf("users_%s.%s", time.Now().Format("20060102150405"), queryDTO.Format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	// 响应已开始输出，出错时只能记录日志并中断
+	if err := ctrl.userService.Export(c.Request.Context(), &queryDTO, c.Writer); err != nil {
+		utils.Errorf("导出用户失败: %v", err)
+		c.Abort()
+	}
+}
+
+// responseUserError 返回用户服务的错误
+// 业务错误返回对应的错误码和消息，其他错误只记录日志，避免向客户端泄露数据库等内部错误
+func responseUserError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		utils.ResponseError(c, utils.CodeUserNotFound, err.Error())
+	case errors.Is(err, services.ErrUsernameExists), errors.Is(err, services.ErrEmailExists):
+		utils.ResponseError(c, utils.CodeUserExists, err.Error())
+	case errors.Is(err, services.ErrOldPasswordWrong):
+		utils.ResponseError(c, utils.CodePasswordError, err.Error())
+	case errors.Is(err, utils.ErrPasswordTooShort), errors.Is(err, utils.ErrPasswordTooWeak):
+		utils.ResponseError(c, utils.CodeInvalidParams, err.Error())
+	default:
+		utils.LogAndResponseError(c, utils.CodeInternalError, err)
+	}
+}
+
+// transferFormatFromFilename 根据文件扩展名判断导入格式
+func transferFormatFromFilename(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return validation.TransferFormatCSV
+	case ".ndjson", ".jsonl":
+		return validation.TransferFormatNDJSON
+	default:
+		return ""
+	}
+}
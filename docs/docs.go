@@ -24,6 +24,190 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/v1/admin/audit-logs": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "按实体、操作人和时间范围查询数据变更审计日志，结果按时间倒序排列",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统管理"
+                ],
+                "summary": "审计日志查询",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码，从1开始计数",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页记录数，默认10条",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "实体类型（表名），如users",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "实体ID",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "操作人用户ID",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作类型: create、update或delete",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间，格式: 2006-01-02 15:04:05",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间，格式: 2006-01-02 15:04:05",
+                        "name": "end_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "审计日志列表数据，包含总数和分页记录",
+                        "schema": {
+                            "$ref": "#/definitions/validation.AuditLogListResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/export": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "按条件以CSV或NDJSON格式流式导出用户，不包含密码",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "导出用户",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "csv",
+                        "description": "文件格式: csv或ndjson",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "搜索关键词，支持用户名、邮箱和昵称模糊搜索",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "用户状态",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "用户数据文件",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/import": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "以multipart/form-data流式上传CSV或NDJSON文件（字段名为file）批量导入用户，每条记录按创建用户的规则校验，用户名已存在时更新该用户的邮箱、昵称和状态，密码仅在update_password为true时更新；邮箱已被其他用户使用的记录导入失败。CSV首行为表头，列名为username、password、email、nickname、status",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "批量导入用户",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV或NDJSON文件",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件格式: csv或ndjson，默认根据文件扩展名判断",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "仅校验不写入",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "更新已有用户的密码",
+                        "name": "update_password",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "导入结果，包含失败记录明细",
+                        "schema": {
+                            "$ref": "#/definitions/validation.UserImportResultDTO"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/users": {
             "get": {
                 "security": [
@@ -127,6 +311,135 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/users/me/erase": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "为当前登录用户创建数据删除请求，宽限期结束后匿名化用户资料并删除各模块中的个人数据，宽限期内可以取消",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户隐私"
+                ],
+                "summary": "申请删除个人数据",
+                "responses": {
+                    "200": {
+                        "description": "删除请求，scheduled_at为宽限期结束时间",
+                        "schema": {
+                            "$ref": "#/definitions/validation.PrivacyRequestResponseDTO"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "在宽限期内取消当前登录用户的数据删除请求",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户隐私"
+                ],
+                "summary": "取消删除个人数据",
+                "responses": {
+                    "200": {
+                        "description": "已取消的删除请求",
+                        "schema": {
+                            "$ref": "#/definitions/validation.PrivacyRequestResponseDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "没有可取消的删除请求",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/export": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "异步生成当前登录用户全部个人数据的导出文件。已有处理中或未过期的导出时直接返回该请求，status为completed时可通过download_url下载",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户隐私"
+                ],
+                "summary": "申请导出个人数据",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "zip",
+                        "description": "导出格式: zip(每个模块一个JSON文件)或json(单个JSON文件)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "导出请求",
+                        "schema": {
+                            "$ref": "#/definitions/validation.PrivacyRequestResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/export/download": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "下载当前登录用户最近一次生成且未过期的数据导出文件",
+                "produces": [
+                    "application/zip",
+                    "application/json"
+                ],
+                "tags": [
+                    "用户隐私"
+                ],
+                "summary": "下载个人数据导出文件",
+                "responses": {
+                    "200": {
+                        "description": "导出文件",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "导出文件尚未生成或已过期",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/users/{id}": {
             "get": {
                 "security": [
@@ -261,6 +574,108 @@ const docTemplate = `{
                 }
             }
         },
+        "validation.AuditLogListResponseDTO": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "审计日志列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/validation.AuditLogResponseDTO"
+                    }
+                },
+                "page": {
+                    "description": "当前页码",
+                    "type": "integer"
+                },
+                "page_size": {
+                    "description": "每页数量",
+                    "type": "integer"
+                },
+                "pages": {
+                    "description": "总页数",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "总数",
+                    "type": "integer"
+                }
+            }
+        },
+        "validation.AuditLogResponseDTO": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "changes": {
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "entity_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "validation.PrivacyRequestResponseDTO": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "description": "完成时间",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "申请时间",
+                    "type": "string"
+                },
+                "download_url": {
+                    "description": "导出文件下载地址",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "失败原因",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "导出文件过期时间",
+                    "type": "string"
+                },
+                "format": {
+                    "description": "导出格式",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "请求ID",
+                    "type": "integer"
+                },
+                "scheduled_at": {
+                    "description": "计划执行时间，删除请求为宽限期结束时间",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "处理状态",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "请求类型: export或erasure",
+                    "type": "string"
+                }
+            }
+        },
         "validation.UserChangePasswordDTO": {
             "type": "object",
             "required": [
@@ -278,6 +693,55 @@ const docTemplate = `{
                 }
             }
         },
+        "validation.UserImportErrorDTO": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "错误信息",
+                    "type": "string"
+                },
+                "row": {
+                    "description": "记录序号，从1开始，不含CSV表头",
+                    "type": "integer"
+                },
+                "username": {
+                    "description": "用户名",
+                    "type": "string"
+                }
+            }
+        },
+        "validation.UserImportResultDTO": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "description": "是否仅校验",
+                    "type": "boolean"
+                },
+                "errors": {
+                    "description": "失败记录明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/validation.UserImportErrorDTO"
+                    }
+                },
+                "errors_truncated": {
+                    "description": "失败记录过多时明细被截断",
+                    "type": "boolean"
+                },
+                "failed": {
+                    "description": "失败数",
+                    "type": "integer"
+                },
+                "succeeded": {
+                    "description": "成功数（仅校验时为校验通过数）",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "记录总数",
+                    "type": "integer"
+                }
+            }
+        },
         "validation.UserListResponseDTO": {
             "type": "object",
             "properties": {
@@ -0,0 +1,183 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"strconv"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/mysql"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// 临时开启SQL日志的默认时长和最长时长.
+const (
+	defaultSQLLogDuration = 5 * time.Minute
+	maxSQLLogDuration     = 30 * time.Minute
+)
+
+// ErrPublicAddr 管理接口的监听地址不是本机或内网地址.
+var ErrPublicAddr = errors.New("管理接口只能监听本机或内网地址")
+
+// Route 已注册的路由.
+type Route struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"`
+}
+
+// ValidateAddr 校验管理接口的监听地址.
+// 地址必须显式指定本机回环地址或内网地址，且不能与公网服务的端口相同.
+func ValidateAddr(addr string, publicPort int) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("管理接口监听地址格式错误: %w", err)
+	}
+	if port == strconv.Itoa(publicPort) {
+		return fmt.Errorf("管理接口不能与公网服务使用同一端口 %s", port)
+	}
+
+	if host == "localhost" {
+		return nil
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsUnspecified() || !(ip.IsLoopback() || ip.IsPrivate()) {
+		return fmt.Errorf("%w: %s", ErrPublicAddr, addr)
+	}
+	return nil
+}
+
+// Handler 创建管理接口.
+// 提供 pprof、expvar、已注册的路由列表，以及日志级别、SQL日志和 goroutine 转储等运行时操作.
+func Handler(engine *gin.Engine) http.Handler {
+	mux := http.NewServeMux()
+
+	// pprof 性能分析
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	// expvar 运行时变量
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	mux.HandleFunc("/debug/routes", routesHandler(engine))
+	mux.HandleFunc("/debug/loglevel", logLevelHandler)
+	mux.HandleFunc("/debug/sqllog", sqlLogHandler)
+	mux.HandleFunc("/debug/goroutines", goroutinesHandler)
+
+	return mux
+}
+
+// routesHandler 列出 gin 引擎上注册的路由.
+func routesHandler(engine *gin.Engine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+
+		infos := engine.Routes()
+		routes := make([]Route, 0, len(infos))
+		for _, info := range infos {
+			routes = append(routes, Route{Method: info.Method, Path: info.Path, Handler: info.Handler})
+		}
+		writeJSON(w, http.StatusOK, routes)
+	}
+}
+
+// logLevelHandler 查看或修改日志级别.
+// GET 返回当前级别，PUT 或 POST 通过 level 参数设置级别，如 level=debug.
+func logLevelHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		level, err := utils.ParseLogLevel(r.FormValue("level"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		previous := utils.GetLogLevel()
+		utils.SetLogLevel(level)
+		utils.Warnf("日志级别已由管理接口从 %s 修改为 %s", utils.LogLevelName(previous), utils.LogLevelName(level))
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPost)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"level": utils.LogLevelName(utils.GetLogLevel())})
+}
+
+// sqlLogHandler 查看或临时开启SQL日志.
+// POST 开启，duration 参数指定时长（如 10m），默认5分钟，最长30分钟；DELETE 立即关闭.
+func sqlLogHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPut:
+		duration := defaultSQLLogDuration
+		if value := r.FormValue("duration"); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed <= 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "duration 参数格式错误"})
+				return
+			}
+			duration = min(parsed, maxSQLLogDuration)
+		}
+		mysql.EnableSQLLog(duration)
+		utils.Warnf("SQL日志已由管理接口开启 %s", duration)
+	case http.MethodDelete:
+		mysql.EnableSQLLog(0)
+		utils.Warnf("SQL日志已由管理接口关闭")
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete)
+		return
+	}
+
+	status := map[string]interface{}{"enabled": false}
+	if until := mysql.SQLLogUntil(); !until.IsZero() {
+		status["enabled"] = true
+		status["until"] = until
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// goroutinesHandler 以文本形式转储所有 goroutine 的调用栈.
+func goroutinesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Goroutine-Count", strconv.Itoa(runtime.NumGoroutine()))
+	if err := rpprof.Lookup("goroutine").WriteTo(w, 2); err != nil {
+		utils.Errorf("转储goroutine失败: %v", err)
+	}
+}
+
+// methodNotAllowed 返回405.
+func methodNotAllowed(w http.ResponseWriter, methods ...string) {
+	for _, method := range methods {
+		w.Header().Add("Allow", method)
+	}
+	writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+}
+
+// writeJSON 输出JSON响应.
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		utils.Errorf("输出管理接口响应失败: %v", err)
+	}
+}
@@ -0,0 +1,325 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gitee.com/NextEraAbyss/gin-template/models"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// beforeStateKey 变更前快照在语句实例中的存储键.
+	beforeStateKey = "audit:before_state"
+	// maskedValue 敏感字段的脱敏值.
+	maskedValue = "******"
+)
+
+// Change 单个字段的变更内容.
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Plugin 审计日志插件.
+// 通过gorm回调自动记录被审计模型的创建、更新和删除操作，
+// 操作人和请求ID从上下文中获取（分别由 AuthMiddleware 和 RequestID 中间件写入）.
+// 字段标签为 audit:"-" 的字段只记录是否变更，不记录具体值.
+type Plugin struct {
+	models []interface{}
+	tables map[string]bool
+}
+
+// NewPlugin 创建审计日志插件，models 为需要审计的模型.
+func NewPlugin(models ...interface{}) *Plugin {
+	return &Plugin{
+		models: models,
+		tables: make(map[string]bool),
+	}
+}
+
+// Name 插件名称.
+func (p *Plugin) Name() string {
+	return "audit"
+}
+
+// Initialize 注册审计回调.
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	for _, model := range p.models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("解析审计模型失败: %w", err)
+		}
+		p.tables[stmt.Schema.Table] = true
+	}
+
+	if err := db.Callback().Create().After("gorm:create").Register("audit:after_create", p.afterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("audit:before_update", p.captureBefore); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("audit:after_update", p.afterUpdate); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", p.captureBefore); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("audit:after_delete", p.afterDelete)
+}
+
+// audited 判断当前语句是否需要审计.
+func (p *Plugin) audited(db *gorm.DB) bool {
+	stmt := db.Statement
+	return db.Error == nil && stmt.Schema != nil && stmt.Schema.PrioritizedPrimaryField != nil && p.tables[stmt.Schema.Table]
+}
+
+// captureBefore 在更新或删除前保存受影响记录的快照.
+func (p *Plugin) captureBefore(db *gorm.DB) {
+	if !p.audited(db) {
+		return
+	}
+
+	stmt := db.Statement
+	conds := make([]clause.Expression, 0, 2)
+	if where, ok := stmt.Clauses["WHERE"]; ok {
+		if w, ok := where.Expression.(clause.Where); ok && len(w.Exprs) > 0 {
+			conds = append(conds, clause.Where{Exprs: w.Exprs})
+		}
+	}
+
+	// 模型自身带有主键时（例如 Save），以主键定位记录
+	if stmt.ReflectValue.Kind() == reflect.Struct {
+		pk := stmt.Schema.PrioritizedPrimaryField
+		if value, zero := pk.ValueOf(stmt.Context, stmt.ReflectValue); !zero {
+			conds = append(conds, clause.Eq{Column: clause.Column{Table: stmt.Table, Name: pk.DBName}, Value: value})
+		}
+	}
+
+	// 没有任何条件时不做快照，避免全表扫描
+	if len(conds) == 0 {
+		return
+	}
+
+	records, err := p.load(db, conds...)
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("读取审计快照失败: %w", err))
+		return
+	}
+	db.InstanceSet(beforeStateKey, records)
+}
+
+// afterCreate 记录创建操作.
+func (p *Plugin) afterCreate(db *gorm.DB) {
+	if !p.audited(db) {
+		return
+	}
+
+	stmt := db.Statement
+	var logs []models.AuditLog
+	eachRecord(stmt.ReflectValue, func(rv reflect.Value) {
+		after := toRecord(stmt.Context, stmt.Schema, rv)
+		if log, ok := p.buildLog(stmt, models.AuditActionCreate, nil, after); ok {
+			logs = append(logs, log)
+		}
+	})
+	p.save(db, logs)
+}
+
+// afterUpdate 记录更新操作.
+func (p *Plugin) afterUpdate(db *gorm.DB) {
+	before, ok := p.beforeState(db)
+	if !ok {
+		return
+	}
+
+	stmt := db.Statement
+	pk := stmt.Schema.PrioritizedPrimaryField
+	ids := make([]interface{}, 0, len(before))
+	for _, record := range before {
+		ids = append(ids, record[pk.DBName])
+	}
+
+	after, err := p.load(db, clause.IN{Column: clause.Column{Table: stmt.Table, Name: pk.DBName}, Values: ids})
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("读取审计快照失败: %w", err))
+		return
+	}
+
+	afterByID := make(map[string]map[string]interface{}, len(after))
+	for _, record := range after {
+		afterByID[fmt.Sprint(record[pk.DBName])] = record
+	}
+
+	logs := make([]models.AuditLog, 0, len(before))
+	for _, record := range before {
+		if log, ok := p.buildLog(stmt, models.AuditActionUpdate, record, afterByID[fmt.Sprint(record[pk.DBName])]); ok {
+			logs = append(logs, log)
+		}
+	}
+	p.save(db, logs)
+}
+
+// afterDelete 记录删除操作.
+func (p *Plugin) afterDelete(db *gorm.DB) {
+	before, ok := p.beforeState(db)
+	if !ok {
+		return
+	}
+
+	logs := make([]models.AuditLog, 0, len(before))
+	for _, record := range before {
+		if log, ok := p.buildLog(db.Statement, models.AuditActionDelete, record, nil); ok {
+			logs = append(logs, log)
+		}
+	}
+	p.save(db, logs)
+}
+
+// beforeState 获取变更前快照.
+func (p *Plugin) beforeState(db *gorm.DB) ([]map[string]interface{}, bool) {
+	if !p.audited(db) {
+		return nil, false
+	}
+
+	value, ok := db.InstanceGet(beforeStateKey)
+	if !ok {
+		return nil, false
+	}
+	records, ok := value.([]map[string]interface{})
+	return records, ok && len(records) > 0
+}
+
+// load 按条件加载记录快照.
+func (p *Plugin) load(db *gorm.DB, conds ...clause.Expression) ([]map[string]interface{}, error) {
+	stmt := db.Statement
+	rows := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+
+	err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).
+		Table(stmt.Table).
+		Clauses(conds...).
+		Find(rows.Interface()).Error
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]map[string]interface{}, 0, rows.Elem().Len())
+	eachRecord(rows.Elem(), func(rv reflect.Value) {
+		records = append(records, toRecord(stmt.Context, stmt.Schema, rv))
+	})
+	return records, nil
+}
+
+// buildLog 根据变更前后的快照构建审计日志，没有字段变更时返回 false.
+func (p *Plugin) buildLog(stmt *gorm.Statement, action string, before, after map[string]interface{}) (models.AuditLog, bool) {
+	changes := make(map[string]Change)
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		// 自动维护的时间字段不计入变更
+		if action == models.AuditActionUpdate && (field.AutoCreateTime > 0 || field.AutoUpdateTime > 0) {
+			continue
+		}
+
+		oldValue, hasOld := before[field.DBName]
+		newValue, hasNew := after[field.DBName]
+		if equal(oldValue, newValue) {
+			continue
+		}
+
+		change := Change{Before: oldValue, After: newValue}
+		if field.Tag.Get("audit") == "-" {
+			change = Change{Before: mask(hasOld), After: mask(hasNew)}
+		}
+		changes[field.DBName] = change
+	}
+
+	if len(changes) == 0 {
+		return models.AuditLog{}, false
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		_ = stmt.AddError(fmt.Errorf("序列化审计变更失败: %w", err))
+		return models.AuditLog{}, false
+	}
+
+	record := after
+	if record == nil {
+		record = before
+	}
+	actorID, _ := utils.UserIDFromContext(stmt.Context)
+
+	return models.AuditLog{
+		ActorID:    actorID,
+		RequestID:  utils.RequestIDFromContext(stmt.Context),
+		Action:     action,
+		EntityType: stmt.Schema.Table,
+		EntityID:   fmt.Sprint(record[stmt.Schema.PrioritizedPrimaryField.DBName]),
+		Changes:    string(data),
+	}, true
+}
+
+// save 在当前连接（事务）中写入审计日志，写入失败时中断原操作.
+func (p *Plugin) save(db *gorm.DB, logs []models.AuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+
+	if err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Create(&logs).Error; err != nil {
+		_ = db.AddError(fmt.Errorf("写入审计日志失败: %w", err))
+	}
+}
+
+// eachRecord 遍历单条或多条记录.
+func eachRecord(rv reflect.Value, fn func(reflect.Value)) {
+	rv = reflect.Indirect(rv)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fn(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fn(rv)
+	}
+}
+
+// toRecord 将模型值转换为 列名 -> 值 的快照.
+func toRecord(ctx context.Context, s *schema.Schema, rv reflect.Value) map[string]interface{} {
+	record := make(map[string]interface{}, len(s.Fields))
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		value, _ := field.ValueOf(ctx, rv)
+		record[field.DBName] = value
+	}
+	return record
+}
+
+// equal 比较两个字段值是否相同，按JSON序列化结果比较以忽略指针和时区表示上的差异.
+func equal(a, b interface{}) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(left) == string(right)
+}
+
+// mask 返回脱敏后的值.
+func mask(present bool) interface{} {
+	if !present {
+		return nil
+	}
+	return maskedValue
+}
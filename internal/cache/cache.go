@@ -2,17 +2,30 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	"gitee.com/NextEraAbyss/gin-template/internal/redis"
 	redisClient "github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 )
 
+// ErrCacheMiss 缓存不存在或已过期.
+var ErrCacheMiss = errors.New("缓存不存在")
+
+// DefaultNamespace 默认的缓存键命名空间.
+const DefaultNamespace = "cache:"
+
+// scanBatchSize 按前缀删除时每次SCAN的键数量.
+const scanBatchSize = 500
+
+// Loader 缓存未命中时加载数据.
+type Loader func(ctx context.Context) (interface{}, error)
+
 // Cache 定义缓存接口.
 type Cache interface {
-	// Get 获取缓存.
+	// Get 获取缓存，不存在时返回 ErrCacheMiss.
 	Get(ctx context.Context, key string, value interface{}) error
 	// Set 设置缓存.
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
@@ -20,41 +33,108 @@ type Cache interface {
 	Delete(ctx context.Context, key string) error
 	// Exists 检查缓存是否存在.
 	Exists(ctx context.Context, key string) (bool, error)
-	// Clear 清空缓存.
+	// Clear 清空当前命名空间下的缓存.
 	Clear(ctx context.Context) error
+	// GetOrLoad 获取缓存，不存在时调用 loader 加载并写入缓存.
+	// 同一个键的并发加载会合并为一次 loader 调用，loader 返回错误时不写入缓存.
+	GetOrLoad(ctx context.Context, key string, value interface{}, expiration time.Duration, loader Loader) error
+	// MGet 批量获取缓存，values 的值为接收数据的指针，返回不存在的键.
+	MGet(ctx context.Context, values map[string]interface{}) ([]string, error)
+	// MSet 批量设置缓存.
+	MSet(ctx context.Context, values map[string]interface{}, expiration time.Duration) error
+	// DeleteByPrefix 删除指定前缀的所有缓存.
+	DeleteByPrefix(ctx context.Context, prefix string) error
+}
+
+// rawCache 按序列化后的数据读写缓存，供各实现复用通用逻辑.
+type rawCache interface {
+	// getRaw 获取序列化后的数据，不存在时返回 ErrCacheMiss.
+	getRaw(ctx context.Context, key string) ([]byte, error)
+	// setRaw 写入序列化后的数据.
+	setRaw(ctx context.Context, key string, data []byte, expiration time.Duration) error
+}
+
+// getOrLoad GetOrLoad 的通用实现.
+func getOrLoad(ctx context.Context, c rawCache, serializer *Serializer, group *singleflight.Group, key string, value interface{}, expiration time.Duration, loader Loader) error {
+	data, err := c.getRaw(ctx, key)
+	if err == nil {
+		return serializer.Decode(data, value)
+	}
+	if !errors.Is(err, ErrCacheMiss) {
+		return err
+	}
+
+	// 加载不受单个调用方的上下文取消影响，避免影响合并到同一次加载的其他调用方
+	loadCtx := context.WithoutCancel(ctx)
+	result, err, _ := group.Do(key, func() (interface{}, error) {
+		loaded, err := loader(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := serializer.Encode(loaded)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.setRaw(loadCtx, key, data, expiration); err != nil && !errors.Is(err, ErrEntryTooLarge) {
+			return nil, err
+		}
+
+		return data, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return serializer.Decode(result.([]byte), value)
+}
+
+// RedisCacheConfig Redis缓存配置.
+type RedisCacheConfig struct {
+	Namespace  string      // 键命名空间，所有键都会加上该前缀，Clear 只删除该命名空间下的键
+	Serializer *Serializer // 序列化器，为空时使用 DefaultSerializer
 }
 
 // RedisCache Redis缓存实现.
 type RedisCache struct {
-	client *redisClient.Client
+	client     *redisClient.Client
+	namespace  string
+	serializer *Serializer
+	group      singleflight.Group
 }
 
 // NewRedisCache 创建Redis缓存实例.
 func NewRedisCache() Cache {
-	return &RedisCache{
-		client: redis.Client,
-	}
+	return NewRedisCacheWithClient(redis.Client)
 }
 
 // NewRedisCacheWithClient 创建Redis缓存实例，使用自定义客户端.
 func NewRedisCacheWithClient(client *redisClient.Client) Cache {
+	return NewRedisCacheWithConfig(client, RedisCacheConfig{Namespace: DefaultNamespace})
+}
+
+// NewRedisCacheWithConfig 创建Redis缓存实例，使用自定义客户端和配置.
+func NewRedisCacheWithConfig(client *redisClient.Client, config RedisCacheConfig) *RedisCache {
+	if config.Serializer == nil {
+		config.Serializer = DefaultSerializer
+	}
+
 	return &RedisCache{
-		client: client,
+		client:     client,
+		namespace:  config.Namespace,
+		serializer: config.Serializer,
 	}
 }
 
 // Get 获取缓存.
 func (c *RedisCache) Get(ctx context.Context, key string, value interface{}) error {
-	data, err := c.client.Get(ctx, key).Bytes()
+	data, err := c.getRaw(ctx, key)
 	if err != nil {
-		if errors.Is(err, redisClient.Nil) {
-			return nil
-		}
-
 		return err
 	}
 
-	return json.Unmarshal(data, value)
+	return c.serializer.Decode(data, value)
 }
 
 // Set 设置缓存.
@@ -62,22 +142,22 @@ func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, exp
 	var data []byte
 	var err error
 
-	data, err = json.Marshal(value)
+	data, err = c.serializer.Encode(value)
 	if err != nil {
 		return err
 	}
 
-	return c.client.Set(ctx, key, data, expiration).Err()
+	return c.setRaw(ctx, key, data, expiration)
 }
 
 // Delete 删除缓存.
 func (c *RedisCache) Delete(ctx context.Context, key string) error {
-	return c.client.Del(ctx, key).Err()
+	return c.client.Del(ctx, c.key(key)).Err()
 }
 
 // Exists 检查缓存是否存在.
 func (c *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
-	result, err := c.client.Exists(ctx, key).Result()
+	result, err := c.client.Exists(ctx, c.key(key)).Result()
 	if err != nil {
 		return false, err
 	}
@@ -85,70 +165,185 @@ func (c *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
 	return result > 0, nil
 }
 
-// Clear 清空缓存.
+// Clear 清空当前命名空间下的缓存.
+// 未配置命名空间时拒绝执行，避免删除共用同一个Redis库的其他应用的数据.
 func (c *RedisCache) Clear(ctx context.Context) error {
-	return c.client.FlushDB(ctx).Err()
+	if c.namespace == "" {
+		return errors.New("未配置缓存命名空间，拒绝清空缓存")
+	}
+
+	return c.DeleteByPrefix(ctx, "")
 }
 
-// MemoryCache 内存缓存实现.
-type MemoryCache struct {
-	data map[string][]byte
+// GetOrLoad 获取缓存，不存在时调用 loader 加载并写入缓存.
+func (c *RedisCache) GetOrLoad(ctx context.Context, key string, value interface{}, expiration time.Duration, loader Loader) error {
+	return getOrLoad(ctx, c, c.serializer, &c.group, key, value, expiration, loader)
 }
 
-// NewMemoryCache 创建内存缓存实例.
-func NewMemoryCache() Cache {
-	return &MemoryCache{
-		data: make(map[string][]byte),
+// MGet 批量获取缓存.
+func (c *RedisCache) MGet(ctx context.Context, values map[string]interface{}) ([]string, error) {
+	data, err := c.mgetRaw(ctx, mapKeys(values))
+	if err != nil {
+		return nil, err
 	}
+
+	return decodeMulti(c.serializer, values, data)
 }
 
-// Get 获取缓存.
-func (c *MemoryCache) Get(ctx context.Context, key string, value interface{}) error {
-	var data []byte
-	var exists bool
+// MSet 批量设置缓存.
+func (c *RedisCache) MSet(ctx context.Context, values map[string]interface{}, expiration time.Duration) error {
+	data, err := encodeMulti(c.serializer, values)
+	if err != nil {
+		return err
+	}
 
-	data, exists = c.data[key]
-	if !exists {
-		return nil
+	return c.msetRaw(ctx, data, expiration)
+}
+
+// DeleteByPrefix 删除指定前缀的所有缓存.
+// 使用SCAN分批遍历，不会像KEYS一样阻塞Redis.
+func (c *RedisCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	pattern := escapePattern(c.key(prefix)) + "*"
+
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, pattern, scanBatchSize).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := c.client.Unlink(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// getRaw 获取序列化后的数据.
+func (c *RedisCache) getRaw(ctx context.Context, key string) ([]byte, error) {
+	data, err := c.client.Get(ctx, c.key(key)).Bytes()
+	if err != nil {
+		if errors.Is(err, redisClient.Nil) {
+			return nil, ErrCacheMiss
+		}
+
+		return nil, err
 	}
 
-	return json.Unmarshal(data, value)
+	return data, nil
 }
 
-// Set 设置缓存.
-func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	var data []byte
-	var err error
+// setRaw 写入序列化后的数据.
+func (c *RedisCache) setRaw(ctx context.Context, key string, data []byte, expiration time.Duration) error {
+	return c.client.Set(ctx, c.key(key), data, expiration).Err()
+}
+
+// mgetRaw 批量获取序列化后的数据，不存在的键不包含在结果中.
+func (c *RedisCache) mgetRaw(ctx context.Context, keys []string) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = c.key(key)
+	}
 
-	data, err = json.Marshal(value)
+	items, err := c.client.MGet(ctx, fullKeys...).Result()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	c.data[key] = data
+	for i, item := range items {
+		if s, ok := item.(string); ok {
+			result[keys[i]] = []byte(s)
+		}
+	}
 
-	return nil
+	return result, nil
 }
 
-// Delete 删除缓存.
-func (c *MemoryCache) Delete(ctx context.Context, key string) error {
-	delete(c.data, key)
+// msetRaw 批量写入序列化后的数据.
+// MSET不支持过期时间，因此使用管道批量执行SET.
+func (c *RedisCache) msetRaw(ctx context.Context, data map[string][]byte, expiration time.Duration) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redisClient.Pipeliner) error {
+		for key, value := range data {
+			pipe.Set(ctx, c.key(key), value, expiration)
+		}
+		return nil
+	})
 
-	return nil
+	return err
 }
 
-// Exists 检查缓存是否存在.
-func (c *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
-	var exists bool
+// key 为键加上命名空间前缀.
+func (c *RedisCache) key(key string) string {
+	return c.namespace + key
+}
 
-	_, exists = c.data[key]
+// escapePattern 转义SCAN匹配模式中的特殊字符.
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
 
-	return exists, nil
+	return b.String()
 }
 
-// Clear 清空缓存.
-func (c *MemoryCache) Clear(ctx context.Context) error {
-	c.data = make(map[string][]byte)
+// mapKeys 返回 map 的所有键.
+func mapKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// encodeMulti 批量序列化缓存数据.
+func encodeMulti(serializer *Serializer, values map[string]interface{}) (map[string][]byte, error) {
+	data := make(map[string][]byte, len(values))
+	for key, value := range values {
+		encoded, err := serializer.Encode(value)
+		if err != nil {
+			return nil, err
+		}
+		data[key] = encoded
+	}
+
+	return data, nil
+}
+
+// decodeMulti 将批量获取的数据反序列化到 values 中，返回不存在的键.
+func decodeMulti(serializer *Serializer, values map[string]interface{}, data map[string][]byte) ([]string, error) {
+	var missing []string
+	for key, value := range values {
+		encoded, ok := data[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+
+		if err := serializer.Decode(encoded, value); err != nil {
+			return nil, err
+		}
+	}
 
-	return nil
+	return missing, nil
 }
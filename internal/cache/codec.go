@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// 编解码器ID，写入缓存数据的首字节.
+const (
+	CodecJSON    byte = 1
+	CodecMsgpack byte = 2
+	CodecGob     byte = 3
+)
+
+// compressedFlag 首字节中表示数据经过zstd压缩的标志位.
+const compressedFlag byte = 0x80
+
+// Codec 缓存数据编解码器.
+type Codec interface {
+	// ID 编解码器ID，写入缓存数据首字节，读取时据此选择编解码器.
+	ID() byte
+	// Name 编解码器名称.
+	Name() string
+	// Marshal 序列化.
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal 反序列化.
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// jsonCodec JSON编解码器.
+type jsonCodec struct{}
+
+func (jsonCodec) ID() byte                                   { return CodecJSON }
+func (jsonCodec) Name() string                               { return "json" }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// msgpackCodec MessagePack编解码器，与JSON使用相同的结构体标签.
+type msgpackCodec struct{}
+
+func (msgpackCodec) ID() byte     { return CodecMsgpack }
+func (msgpackCodec) Name() string { return "msgpack" }
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	decoder.SetCustomStructTag("json")
+
+	return decoder.Decode(v)
+}
+
+// gobCodec gob编解码器，保留 time.Time 时区等Go类型信息，但不识别JSON标签.
+type gobCodec struct{}
+
+func (gobCodec) ID() byte     { return CodecGob }
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// 内置编解码器.
+var (
+	JSONCodec    Codec = jsonCodec{}
+	MsgpackCodec Codec = msgpackCodec{}
+	GobCodec     Codec = gobCodec{}
+)
+
+// codecs 按ID索引的编解码器，读取时不依赖当前配置，因此切换编解码器无需清空缓存.
+var codecs = map[byte]Codec{
+	CodecJSON:    JSONCodec,
+	CodecMsgpack: MsgpackCodec,
+	CodecGob:     GobCodec,
+}
+
+// CodecByName 根据名称获取内置编解码器.
+func CodecByName(name string) (Codec, error) {
+	for _, codec := range codecs {
+		if codec.Name() == name {
+			return codec, nil
+		}
+	}
+
+	return nil, fmt.Errorf("不支持的缓存编解码器: %s", name)
+}
+
+// zstd编码器和解码器可并发使用，全局共享.
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+		return zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+	})
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	})
+)
+
+// Serializer 缓存数据序列化器.
+// 序列化结果的首字节记录编解码器ID和压缩标志，读取时自动识别，
+// 没有首字节标识的旧数据按JSON解析.
+type Serializer struct {
+	codec             Codec
+	compressThreshold int
+}
+
+// DefaultSerializer 默认序列化器，使用JSON且不压缩.
+var DefaultSerializer = NewSerializer(JSONCodec, 0)
+
+// NewSerializer 创建序列化器.
+// compressThreshold 大于0时，超过该字节数的数据使用zstd压缩.
+func NewSerializer(codec Codec, compressThreshold int) *Serializer {
+	if codec == nil {
+		codec = JSONCodec
+	}
+
+	return &Serializer{
+		codec:             codec,
+		compressThreshold: compressThreshold,
+	}
+}
+
+// Encode 序列化缓存数据.
+func (s *Serializer) Encode(v interface{}) ([]byte, error) {
+	payload, err := s.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	header := s.codec.ID()
+	if s.compressThreshold > 0 && len(payload) > s.compressThreshold {
+		encoder, err := zstdEncoder()
+		if err != nil {
+			return nil, err
+		}
+
+		compressed := encoder.EncodeAll(payload, make([]byte, 1, len(payload)/2+1))
+		// 压缩收益不明显时保存原始数据
+		if len(compressed)-1 < len(payload) {
+			compressed[0] = header | compressedFlag
+			return compressed, nil
+		}
+	}
+
+	data := make([]byte, len(payload)+1)
+	data[0] = header
+	copy(data[1:], payload)
+
+	return data, nil
+}
+
+// Decode 反序列化缓存数据.
+func (s *Serializer) Decode(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return fmt.Errorf("缓存数据为空")
+	}
+
+	header := data[0]
+	codec, ok := codecs[header&^compressedFlag]
+	if !ok {
+		// 旧版本直接写入的JSON数据
+		return json.Unmarshal(data, v)
+	}
+
+	payload := data[1:]
+	if header&compressedFlag != 0 {
+		decoder, err := zstdDecoder()
+		if err != nil {
+			return err
+		}
+
+		payload, err = decoder.DecodeAll(payload, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return codec.Unmarshal(payload, v)
+}
@@ -0,0 +1,321 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"github.com/google/uuid"
+	redisClient "github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultInvalidationChannel 默认的缓存失效广播频道.
+const DefaultInvalidationChannel = "cache:invalidate"
+
+// LayeredCacheConfig 两级缓存配置.
+type LayeredCacheConfig struct {
+	L1TTL   time.Duration // 本地缓存有效期，应短于Redis缓存有效期，用于限制错过失效广播时的不一致时间
+	Channel string        // 缓存失效广播的Redis频道
+}
+
+// DefaultLayeredCacheConfig 返回默认两级缓存配置.
+func DefaultLayeredCacheConfig() LayeredCacheConfig {
+	return LayeredCacheConfig{
+		L1TTL:   30 * time.Second,
+		Channel: DefaultInvalidationChannel,
+	}
+}
+
+// invalidation 缓存失效广播消息.
+type invalidation struct {
+	Origin string   `json:"origin"`           // 发送消息的实例ID，实例忽略自己发出的消息
+	Keys   []string `json:"keys,omitempty"`   // 失效的键
+	Prefix *string  `json:"prefix,omitempty"` // 失效的键前缀
+	All    bool     `json:"all,omitempty"`    // 是否清空全部本地缓存
+}
+
+// LayeredCache 两级缓存实现.
+// 读取时依次查询本地内存缓存（L1）和Redis（L2），L2命中时回填L1；
+// 写入和删除时更新L2和本地L1，并通过Redis发布订阅通知其他实例删除各自的L1副本.
+// 两级缓存之间直接复制序列化后的数据，统一使用L2的序列化器.
+type LayeredCache struct {
+	l1       *MemoryCache
+	l2       *RedisCache
+	config   LayeredCacheConfig
+	instance string
+	group    singleflight.Group
+
+	pubsub    *redisClient.PubSub
+	closeOnce sync.Once
+}
+
+// NewLayeredCache 创建两级缓存实例并订阅缓存失效广播.
+// 不再使用时应调用 Close 取消订阅.
+func NewLayeredCache(l2 *RedisCache, l1 *MemoryCache, config LayeredCacheConfig) *LayeredCache {
+	defaults := DefaultLayeredCacheConfig()
+	if config.L1TTL <= 0 {
+		config.L1TTL = defaults.L1TTL
+	}
+	if config.Channel == "" {
+		config.Channel = defaults.Channel
+	}
+
+	c := &LayeredCache{
+		l1:       l1,
+		l2:       l2,
+		config:   config,
+		instance: uuid.NewString(),
+	}
+
+	c.pubsub = l2.client.Subscribe(context.Background(), config.Channel)
+	go c.listen()
+
+	return c
+}
+
+// Get 获取缓存.
+func (c *LayeredCache) Get(ctx context.Context, key string, value interface{}) error {
+	data, err := c.getRaw(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return c.l2.serializer.Decode(data, value)
+}
+
+// Set 设置缓存.
+func (c *LayeredCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := c.l2.serializer.Encode(value)
+	if err != nil {
+		return err
+	}
+
+	return c.setRaw(ctx, key, data, expiration)
+}
+
+// Delete 删除缓存.
+func (c *LayeredCache) Delete(ctx context.Context, key string) error {
+	if err := c.l2.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	if err := c.l1.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	return c.publish(ctx, invalidation{Keys: []string{key}})
+}
+
+// Exists 检查缓存是否存在.
+func (c *LayeredCache) Exists(ctx context.Context, key string) (bool, error) {
+	if exists, _ := c.l1.Exists(ctx, key); exists {
+		return true, nil
+	}
+
+	return c.l2.Exists(ctx, key)
+}
+
+// Clear 清空当前命名空间下的缓存.
+func (c *LayeredCache) Clear(ctx context.Context) error {
+	if err := c.l2.Clear(ctx); err != nil {
+		return err
+	}
+
+	if err := c.l1.Clear(ctx); err != nil {
+		return err
+	}
+
+	return c.publish(ctx, invalidation{All: true})
+}
+
+// GetOrLoad 获取缓存，不存在时调用 loader 加载并写入缓存.
+func (c *LayeredCache) GetOrLoad(ctx context.Context, key string, value interface{}, expiration time.Duration, loader Loader) error {
+	return getOrLoad(ctx, c, c.l2.serializer, &c.group, key, value, expiration, loader)
+}
+
+// MGet 批量获取缓存，L1未命中的键通过一次管道请求从L2获取.
+func (c *LayeredCache) MGet(ctx context.Context, values map[string]interface{}) ([]string, error) {
+	data := make(map[string][]byte, len(values))
+	var misses []string
+	for key := range values {
+		if encoded, err := c.l1.getRaw(ctx, key); err == nil {
+			data[key] = encoded
+		} else {
+			misses = append(misses, key)
+		}
+	}
+
+	if len(misses) > 0 {
+		gets := make([]*redisClient.StringCmd, len(misses))
+		ttls := make([]*redisClient.DurationCmd, len(misses))
+		_, err := c.l2.client.Pipelined(ctx, func(pipe redisClient.Pipeliner) error {
+			for i, key := range misses {
+				gets[i] = pipe.Get(ctx, c.l2.key(key))
+				ttls[i] = pipe.PTTL(ctx, c.l2.key(key))
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, redisClient.Nil) {
+			return nil, err
+		}
+
+		for i, key := range misses {
+			encoded, err := gets[i].Bytes()
+			if err != nil {
+				continue
+			}
+
+			data[key] = encoded
+			c.fillL1(ctx, key, encoded, ttls[i].Val())
+		}
+	}
+
+	return decodeMulti(c.l2.serializer, values, data)
+}
+
+// MSet 批量设置缓存.
+func (c *LayeredCache) MSet(ctx context.Context, values map[string]interface{}, expiration time.Duration) error {
+	data, err := encodeMulti(c.l2.serializer, values)
+	if err != nil {
+		return err
+	}
+
+	if err := c.l2.msetRaw(ctx, data, expiration); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(data))
+	for key, encoded := range data {
+		c.fillL1(ctx, key, encoded, expiration)
+		keys = append(keys, key)
+	}
+
+	return c.publish(ctx, invalidation{Keys: keys})
+}
+
+// DeleteByPrefix 删除指定前缀的所有缓存.
+func (c *LayeredCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	if err := c.l2.DeleteByPrefix(ctx, prefix); err != nil {
+		return err
+	}
+
+	if err := c.l1.DeleteByPrefix(ctx, prefix); err != nil {
+		return err
+	}
+
+	return c.publish(ctx, invalidation{Prefix: &prefix})
+}
+
+// Stats 获取本地缓存统计信息.
+func (c *LayeredCache) Stats() MemoryCacheStats {
+	return c.l1.Stats()
+}
+
+// Close 取消订阅缓存失效广播并停止本地缓存的后台清理，可重复调用.
+func (c *LayeredCache) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		err = c.pubsub.Close()
+		_ = c.l1.Close()
+	})
+
+	return err
+}
+
+// getRaw 依次从L1和L2获取序列化后的数据，L2命中时回填L1.
+func (c *LayeredCache) getRaw(ctx context.Context, key string) ([]byte, error) {
+	if data, err := c.l1.getRaw(ctx, key); err == nil {
+		return data, nil
+	}
+
+	// 同时获取剩余有效期，避免L1中的副本比L2存活更久
+	var get *redisClient.StringCmd
+	var ttl *redisClient.DurationCmd
+	_, err := c.l2.client.Pipelined(ctx, func(pipe redisClient.Pipeliner) error {
+		get = pipe.Get(ctx, c.l2.key(key))
+		ttl = pipe.PTTL(ctx, c.l2.key(key))
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, redisClient.Nil) {
+			return nil, ErrCacheMiss
+		}
+
+		return nil, err
+	}
+
+	data, err := get.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	c.fillL1(ctx, key, data, ttl.Val())
+	return data, nil
+}
+
+// setRaw 写入L2和本地L1，并通知其他实例删除旧的L1副本.
+func (c *LayeredCache) setRaw(ctx context.Context, key string, data []byte, expiration time.Duration) error {
+	if err := c.l2.setRaw(ctx, key, data, expiration); err != nil {
+		return err
+	}
+
+	c.fillL1(ctx, key, data, expiration)
+
+	return c.publish(ctx, invalidation{Keys: []string{key}})
+}
+
+// fillL1 写入本地缓存，有效期不超过配置的L1有效期和L2剩余有效期.
+// 超过本地缓存容量上限的数据只保存在L2中.
+func (c *LayeredCache) fillL1(ctx context.Context, key string, data []byte, l2TTL time.Duration) {
+	ttl := c.config.L1TTL
+	if l2TTL > 0 && l2TTL < ttl {
+		ttl = l2TTL
+	}
+
+	_ = c.l1.setRaw(ctx, key, data, ttl)
+}
+
+// publish 广播缓存失效消息.
+func (c *LayeredCache) publish(ctx context.Context, msg invalidation) error {
+	msg.Origin = c.instance
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return c.l2.client.Publish(ctx, c.config.Channel, data).Err()
+}
+
+// listen 接收其他实例的缓存失效广播并删除本地副本，直到调用 Close.
+// 连接断开期间错过的广播由较短的L1有效期兜底.
+func (c *LayeredCache) listen() {
+	ctx := context.Background()
+
+	for message := range c.pubsub.Channel() {
+		var msg invalidation
+		if err := json.Unmarshal([]byte(message.Payload), &msg); err != nil {
+			utils.Warnf("无法解析缓存失效消息: %v", err)
+			continue
+		}
+
+		if msg.Origin == c.instance {
+			continue
+		}
+
+		switch {
+		case msg.All:
+			_ = c.l1.Clear(ctx)
+		case msg.Prefix != nil:
+			_ = c.l1.DeleteByPrefix(ctx, *msg.Prefix)
+		default:
+			for _, key := range msg.Keys {
+				_ = c.l1.Delete(ctx, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrEntryTooLarge 单个缓存项超过分片容量上限.
+var ErrEntryTooLarge = errors.New("缓存项超过容量上限")
+
+// MemoryCacheConfig 内存缓存配置.
+type MemoryCacheConfig struct {
+	Shards          int           // 分片数，会向上取整为2的幂
+	MaxEntries      int           // 最大缓存项数，0表示不限制
+	MaxBytes        int64         // 最大占用字节数（键和序列化后的值），0表示不限制
+	CleanupInterval time.Duration // 过期项清理间隔，0表示不启动后台清理
+	Serializer      *Serializer   // 序列化器，为空时使用 DefaultSerializer
+}
+
+// DefaultMemoryCacheConfig 返回默认内存缓存配置.
+func DefaultMemoryCacheConfig() MemoryCacheConfig {
+	return MemoryCacheConfig{
+		Shards:          16,
+		MaxEntries:      100000,
+		MaxBytes:        64 << 20,
+		CleanupInterval: time.Minute,
+	}
+}
+
+// MemoryCacheStats 内存缓存统计信息.
+type MemoryCacheStats struct {
+	Hits        uint64 `json:"hits"`        // 命中次数
+	Misses      uint64 `json:"misses"`      // 未命中次数（包含已过期）
+	Evictions   uint64 `json:"evictions"`   // 因容量上限被淘汰的项数
+	Expirations uint64 `json:"expirations"` // 因过期被清理的项数
+	Entries     int    `json:"entries"`     // 当前缓存项数
+	Bytes       int64  `json:"bytes"`       // 当前占用字节数
+}
+
+// MemoryCache 内存缓存实现.
+// 按键哈希分片，每个分片独立加锁并维护LRU链表，超过容量上限时淘汰最久未使用的项.
+type MemoryCache struct {
+	shards     []*memoryShard
+	mask       uint64
+	serializer *Serializer
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	evictions   atomic.Uint64
+	expirations atomic.Uint64
+
+	group    singleflight.Group
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// memoryShard 缓存分片.
+type memoryShard struct {
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	lru        *list.List // 队首为最近使用的项
+	bytes      int64
+	maxEntries int
+	maxBytes   int64
+}
+
+// memoryEntry 缓存项.
+type memoryEntry struct {
+	key       string
+	data      []byte
+	expiresAt int64 // 过期时间（UnixNano），0表示永不过期
+}
+
+// size 缓存项占用的字节数.
+func (e *memoryEntry) size() int64 {
+	return int64(len(e.key) + len(e.data))
+}
+
+// expired 判断缓存项是否已过期.
+func (e *memoryEntry) expired(now int64) bool {
+	return e.expiresAt > 0 && now >= e.expiresAt
+}
+
+// NewMemoryCache 使用默认配置创建内存缓存实例.
+func NewMemoryCache() Cache {
+	return NewMemoryCacheWithConfig(DefaultMemoryCacheConfig())
+}
+
+// NewMemoryCacheWithConfig 创建内存缓存实例，使用自定义配置.
+// 配置了 CleanupInterval 时会启动后台清理协程，不再使用时应调用 Close 停止.
+func NewMemoryCacheWithConfig(config MemoryCacheConfig) *MemoryCache {
+	shardCount := 1
+	for shardCount < config.Shards {
+		shardCount <<= 1
+	}
+
+	if config.Serializer == nil {
+		config.Serializer = DefaultSerializer
+	}
+
+	c := &MemoryCache{
+		shards:     make([]*memoryShard, shardCount),
+		mask:       uint64(shardCount - 1),
+		serializer: config.Serializer,
+		stop:       make(chan struct{}),
+	}
+
+	// 容量上限平均分配到各分片
+	maxEntries := 0
+	if config.MaxEntries > 0 {
+		maxEntries = (config.MaxEntries + shardCount - 1) / shardCount
+	}
+	var maxBytes int64
+	if config.MaxBytes > 0 {
+		maxBytes = (config.MaxBytes + int64(shardCount) - 1) / int64(shardCount)
+	}
+
+	for i := range c.shards {
+		c.shards[i] = &memoryShard{
+			items:      make(map[string]*list.Element),
+			lru:        list.New(),
+			maxEntries: maxEntries,
+			maxBytes:   maxBytes,
+		}
+	}
+
+	if config.CleanupInterval > 0 {
+		go c.janitor(config.CleanupInterval)
+	}
+
+	return c
+}
+
+// Get 获取缓存.
+func (c *MemoryCache) Get(ctx context.Context, key string, value interface{}) error {
+	data, err := c.getRaw(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return c.serializer.Decode(data, value)
+}
+
+// Set 设置缓存，expiration 小于等于0表示永不过期.
+func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := c.serializer.Encode(value)
+	if err != nil {
+		return err
+	}
+
+	return c.setRaw(ctx, key, data, expiration)
+}
+
+// Delete 删除缓存.
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	shard := c.shard(key)
+
+	shard.mu.Lock()
+	if element, exists := shard.items[key]; exists {
+		shard.remove(element)
+	}
+	shard.mu.Unlock()
+
+	return nil
+}
+
+// Exists 检查缓存是否存在，不影响LRU顺序和命中统计.
+func (c *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	shard := c.shard(key)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	element, exists := shard.items[key]
+	if !exists {
+		return false, nil
+	}
+
+	return !element.Value.(*memoryEntry).expired(time.Now().UnixNano()), nil
+}
+
+// Clear 清空缓存.
+func (c *MemoryCache) Clear(ctx context.Context) error {
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		shard.items = make(map[string]*list.Element)
+		shard.lru.Init()
+		shard.bytes = 0
+		shard.mu.Unlock()
+	}
+
+	return nil
+}
+
+// GetOrLoad 获取缓存，不存在时调用 loader 加载并写入缓存.
+func (c *MemoryCache) GetOrLoad(ctx context.Context, key string, value interface{}, expiration time.Duration, loader Loader) error {
+	return getOrLoad(ctx, c, c.serializer, &c.group, key, value, expiration, loader)
+}
+
+// MGet 批量获取缓存.
+func (c *MemoryCache) MGet(ctx context.Context, values map[string]interface{}) ([]string, error) {
+	data := make(map[string][]byte, len(values))
+	for key := range values {
+		if encoded, err := c.getRaw(ctx, key); err == nil {
+			data[key] = encoded
+		}
+	}
+
+	return decodeMulti(c.serializer, values, data)
+}
+
+// MSet 批量设置缓存.
+func (c *MemoryCache) MSet(ctx context.Context, values map[string]interface{}, expiration time.Duration) error {
+	data, err := encodeMulti(c.serializer, values)
+	if err != nil {
+		return err
+	}
+
+	for key, encoded := range data {
+		if err := c.setRaw(ctx, key, encoded, expiration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteByPrefix 删除指定前缀的所有缓存.
+func (c *MemoryCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		for key, element := range shard.items {
+			if strings.HasPrefix(key, prefix) {
+				shard.remove(element)
+			}
+		}
+		shard.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Stats 获取缓存统计信息.
+func (c *MemoryCache) Stats() MemoryCacheStats {
+	stats := MemoryCacheStats{
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Evictions:   c.evictions.Load(),
+		Expirations: c.expirations.Load(),
+	}
+
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		stats.Entries += len(shard.items)
+		stats.Bytes += shard.bytes
+		shard.mu.Unlock()
+	}
+
+	return stats
+}
+
+// Close 停止后台清理协程，可重复调用.
+func (c *MemoryCache) Close() error {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+
+	return nil
+}
+
+// DeleteExpired 清理所有已过期的缓存项.
+func (c *MemoryCache) DeleteExpired() {
+	now := time.Now().UnixNano()
+
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		for _, element := range shard.items {
+			if element.Value.(*memoryEntry).expired(now) {
+				shard.remove(element)
+				c.expirations.Add(1)
+			}
+		}
+		shard.mu.Unlock()
+	}
+}
+
+// janitor 定期清理过期缓存项，直到调用 Close.
+func (c *MemoryCache) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.DeleteExpired()
+		}
+	}
+}
+
+// getRaw 获取序列化后的缓存数据，并更新LRU顺序和命中统计.
+func (c *MemoryCache) getRaw(ctx context.Context, key string) ([]byte, error) {
+	shard := c.shard(key)
+	now := time.Now().UnixNano()
+
+	shard.mu.Lock()
+	element, exists := shard.items[key]
+	if !exists {
+		shard.mu.Unlock()
+		c.misses.Add(1)
+		return nil, ErrCacheMiss
+	}
+
+	entry := element.Value.(*memoryEntry)
+	if entry.expired(now) {
+		shard.remove(element)
+		shard.mu.Unlock()
+		c.misses.Add(1)
+		c.expirations.Add(1)
+		return nil, ErrCacheMiss
+	}
+
+	shard.lru.MoveToFront(element)
+	shard.mu.Unlock()
+
+	c.hits.Add(1)
+	// 缓存的数据不会被原地修改，调用方可以在锁外读取
+	return entry.data, nil
+}
+
+// setRaw 写入序列化后的缓存数据，超过容量上限时淘汰最久未使用的项.
+func (c *MemoryCache) setRaw(ctx context.Context, key string, data []byte, expiration time.Duration) error {
+	entry := &memoryEntry{key: key, data: data}
+	if expiration > 0 {
+		entry.expiresAt = time.Now().Add(expiration).UnixNano()
+	}
+
+	shard := c.shard(key)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if element, exists := shard.items[key]; exists {
+		shard.remove(element)
+	}
+
+	if shard.maxBytes > 0 && entry.size() > shard.maxBytes {
+		return ErrEntryTooLarge
+	}
+
+	shard.items[key] = shard.lru.PushFront(entry)
+	shard.bytes += entry.size()
+
+	// 超过容量上限时从队尾淘汰
+	for shard.overflow() {
+		shard.remove(shard.lru.Back())
+		c.evictions.Add(1)
+	}
+
+	return nil
+}
+
+// shard 获取键所在的分片.
+func (c *MemoryCache) shard(key string) *memoryShard {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	return c.shards[h.Sum64()&c.mask]
+}
+
+// remove 从分片中移除缓存项，调用方需持有锁.
+func (s *memoryShard) remove(element *list.Element) {
+	entry := s.lru.Remove(element).(*memoryEntry)
+	delete(s.items, entry.key)
+	s.bytes -= entry.size()
+}
+
+// overflow 判断分片是否超过容量上限，调用方需持有锁.
+func (s *memoryShard) overflow() bool {
+	if s.lru.Len() == 0 {
+		return false
+	}
+
+	return (s.maxEntries > 0 && s.lru.Len() > s.maxEntries) ||
+		(s.maxBytes > 0 && s.bytes > s.maxBytes)
+}
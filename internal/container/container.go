@@ -1,63 +1,288 @@
 package container
 
 import (
+	"context"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/config"
 	"gitee.com/NextEraAbyss/gin-template/controllers"
+	"gitee.com/NextEraAbyss/gin-template/internal/cache"
+	"gitee.com/NextEraAbyss/gin-template/internal/health"
+	"gitee.com/NextEraAbyss/gin-template/internal/idempotency"
+	"gitee.com/NextEraAbyss/gin-template/internal/metrics"
+	"gitee.com/NextEraAbyss/gin-template/internal/mysql"
+	"gitee.com/NextEraAbyss/gin-template/internal/outbox"
+	"gitee.com/NextEraAbyss/gin-template/internal/privacy"
+	"gitee.com/NextEraAbyss/gin-template/internal/ratelimit"
+	"gitee.com/NextEraAbyss/gin-template/middlewares"
 	"gitee.com/NextEraAbyss/gin-template/repositories"
 	"gitee.com/NextEraAbyss/gin-template/services"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
 // Container 依赖注入容器.
 type Container struct {
+	config       *config.Config
 	db           *gorm.DB
 	redisClient  *redis.Client
+	Cache        cache.Cache
+	RateLimit    *RateLimit
+	Idempotency  idempotency.Store
+	Health       *health.Registry
 	Repositories *Repositories
 	Services     *Services
 	Controllers  *Controllers
+	Events       *Events
+}
+
+// appCacheName 应用缓存在指标中的名称.
+const appCacheName = "app"
+
+// idempotencyLockMarginSeconds 幂等处理中记录的有效期在接口超时时间之外预留的时间（秒），用于写出响应和保存记录.
+const idempotencyLockMarginSeconds = 10
+
+// RateLimit 限流依赖.
+type RateLimit struct {
+	Limiter  ratelimit.Limiter
+	Policies map[string]ratelimit.Policy // 按名称索引的限流策略
 }
 
+// 限流策略名称.
+const (
+	RateLimitGlobal = "global"
+	RateLimitUser   = "user"
+	RateLimitAdmin  = "admin"
+)
+
 // Repositories 仓储层依赖.
 type Repositories struct {
-	User repositories.UserRepository
+	Transactor repositories.Transactor
+	User       repositories.UserRepository
+	AuditLog   repositories.AuditLogRepository
+	Outbox     repositories.OutboxRepository
+	Privacy    repositories.PrivacyRepository
 }
 
 // Services 服务层依赖.
 type Services struct {
-	User services.UserService
+	User     services.UserService
+	AuditLog services.AuditLogService
+	Privacy  services.PrivacyService
+}
+
+// Events 领域事件依赖.
+type Events struct {
+	Bus   *outbox.Bus   // 进程内事件总线，可用于订阅领域事件
+	Relay *outbox.Relay // 发件箱投递器
 }
 
 // Controllers 控制器层依赖
 type Controllers struct {
-	User *controllers.UserController
+	User     *controllers.UserController
+	AuditLog *controllers.AuditLogController
+	Privacy  *controllers.PrivacyController
+	Health   *controllers.HealthController
 }
 
 // NewContainer 创建新的容器实例
-func NewContainer(db *gorm.DB, redisClient *redis.Client) *Container {
+func NewContainer(cfg *config.Config, db *gorm.DB, redisClient *redis.Client) *Container {
 	return &Container{
+		config:      cfg,
 		db:          db,
 		redisClient: redisClient,
 	}
 }
 
+// InitCache 初始化缓存
+// Redis可用时使用本地内存 + Redis两级缓存，否则只使用本地内存缓存
+func (c *Container) InitCache() {
+	codec, err := cache.CodecByName(c.config.Cache.Codec)
+	if err != nil {
+		utils.Warnf("%v，使用JSON编解码器", err)
+		codec = cache.JSONCodec
+	}
+	serializer := cache.NewSerializer(codec, c.config.Cache.CompressThreshold)
+
+	memoryConfig := cache.DefaultMemoryCacheConfig()
+	memoryConfig.Serializer = serializer
+	if c.config.Cache.L1MaxEntries > 0 {
+		memoryConfig.MaxEntries = c.config.Cache.L1MaxEntries
+	}
+	if c.config.Cache.L1MaxMB > 0 {
+		memoryConfig.MaxBytes = int64(c.config.Cache.L1MaxMB) << 20
+	}
+	l1 := cache.NewMemoryCacheWithConfig(memoryConfig)
+
+	if c.redisClient == nil {
+		c.Cache = metrics.InstrumentCache(appCacheName, l1)
+		return
+	}
+
+	l2 := cache.NewRedisCacheWithConfig(c.redisClient, cache.RedisCacheConfig{
+		Namespace:  c.config.Cache.Namespace,
+		Serializer: serializer,
+	})
+	c.Cache = metrics.InstrumentCache(appCacheName, cache.NewLayeredCache(l2, l1, cache.LayeredCacheConfig{
+		L1TTL:   time.Duration(c.config.Cache.L1TTLSeconds) * time.Second,
+		Channel: c.config.Cache.InvalidationChannel,
+	}))
+}
+
+// InitHealth 注册MySQL、Redis和数据库迁移的健康检查
+// 使用默认注册表，以便服务关闭时将就绪状态置为不可用
+func (c *Container) InitHealth() {
+	c.Health = health.DefaultRegistry
+
+	timeout := time.Duration(c.config.Health.CheckTimeoutSeconds) * time.Second
+	cacheTTL := time.Duration(c.config.Health.CheckCacheSeconds) * time.Second
+
+	c.Health.Register(health.Check{
+		Name:     "mysql",
+		Check:    func(ctx context.Context) error { return mysql.Ping(ctx, c.db) },
+		Timeout:  timeout,
+		CacheTTL: cacheTTL,
+		Probes:   []health.Probe{health.Readiness, health.Startup},
+	})
+	c.Health.Register(health.Check{
+		Name:     "migrations",
+		Check:    func(ctx context.Context) error { return mysql.CheckMigrations(ctx, c.db) },
+		Timeout:  timeout,
+		CacheTTL: cacheTTL,
+		Probes:   []health.Probe{health.Readiness, health.Startup},
+	})
+
+	if c.redisClient != nil {
+		c.Health.Register(health.Check{
+			Name:     "redis",
+			Check:    func(ctx context.Context) error { return c.redisClient.Ping(ctx).Err() },
+			Timeout:  timeout,
+			CacheTTL: cacheTTL,
+			Probes:   []health.Probe{health.Readiness},
+		})
+	}
+}
+
+// InitRateLimit 初始化限流器和限流策略
+// Redis可用时使用Redis限流器，Redis出错时降级为进程内限流
+func (c *Container) InitRateLimit() {
+	var limiter ratelimit.Limiter = ratelimit.NewMemoryLimiter()
+	if c.redisClient != nil {
+		limiter = ratelimit.NewFallbackLimiter(ratelimit.NewRedisLimiter(c.redisClient), limiter, 0)
+	}
+
+	specs := map[string]struct{ spec, fallback string }{
+		RateLimitGlobal: {c.config.RateLimit.Global, "1000/1m"},
+		RateLimitUser:   {c.config.RateLimit.User, "300/1m"},
+		RateLimitAdmin:  {c.config.RateLimit.Admin, "120/1m"},
+	}
+
+	policies := make(map[string]ratelimit.Policy, len(specs))
+	for name, s := range specs {
+		policy, err := ratelimit.ParsePolicy(name, s.spec)
+		if err != nil {
+			utils.Warnf("%v，使用默认限流策略 %s", err, s.fallback)
+			policy, _ = ratelimit.ParsePolicy(name, s.fallback)
+		}
+		policies[name] = policy
+	}
+
+	c.RateLimit = &RateLimit{
+		Limiter:  limiter,
+		Policies: policies,
+	}
+}
+
+// InitIdempotency 初始化幂等请求记录存储
+// Redis可用时多实例共享记录，否则只在当前实例有效
+func (c *Container) InitIdempotency() {
+	if c.redisClient == nil {
+		c.Idempotency = idempotency.NewMemoryStore()
+		return
+	}
+
+	c.Idempotency = idempotency.NewRedisStore(c.redisClient)
+}
+
 // InitRepositories 初始化仓储层
 func (c *Container) InitRepositories() {
 	c.Repositories = &Repositories{
-		User: repositories.NewUserRepository(c.db),
+		Transactor: repositories.NewTransactor(c.db),
+		User: repositories.NewCachedUserRepository(repositories.NewUserRepository(c.db), c.Cache,
+			repositories.CachedUserRepositoryConfig{
+				TTL:         time.Duration(c.config.Cache.UserTTLSeconds) * time.Second,
+				NegativeTTL: time.Duration(c.config.Cache.UserNegativeTTLSeconds) * time.Second,
+			}),
+		AuditLog: repositories.NewAuditLogRepository(c.db),
+		Outbox:   repositories.NewOutboxRepository(c.db),
+		Privacy:  repositories.NewPrivacyRepository(c.db),
+	}
+}
+
+// InitEvents 初始化领域事件投递
+func (c *Container) InitEvents() {
+	bus := outbox.NewBus()
+	publishers := []outbox.Publisher{bus}
+
+	if c.config.Outbox.RedisStream != "" && c.redisClient != nil {
+		publishers = append(publishers, outbox.NewRedisStreamPublisher(c.redisClient, c.config.Outbox.RedisStream, 0))
+	}
+
+	for _, url := range c.config.Outbox.WebhookURLs {
+		publishers = append(publishers, outbox.NewWebhookPublisher(url, c.config.Outbox.WebhookSecret))
+	}
+
+	relayConfig := outbox.DefaultRelayConfig()
+	if c.config.Outbox.PollIntervalSeconds > 0 {
+		relayConfig.PollInterval = time.Duration(c.config.Outbox.PollIntervalSeconds) * time.Second
+	}
+	if c.config.Outbox.BatchSize > 0 {
+		relayConfig.BatchSize = c.config.Outbox.BatchSize
+	}
+	if c.config.Outbox.MaxAttempts > 0 {
+		relayConfig.MaxAttempts = c.config.Outbox.MaxAttempts
+	}
+
+	c.Events = &Events{
+		Bus:   bus,
+		Relay: outbox.NewRelay(c.Repositories.Outbox, relayConfig, publishers...),
 	}
 }
 
 // InitServices 初始化服务层
 func (c *Container) InitServices() {
+	userService := services.NewUserService(c.Repositories.User, c.Repositories.Outbox, c.Repositories.Transactor)
+
+	// 注册各模块的隐私数据处理器，删除时按注册顺序执行
+	privacyRegistry := privacy.NewRegistry()
+	privacyRegistry.MustRegister(
+		services.NewUserPrivacyModule(userService),
+		services.NewAuditLogPrivacyModule(c.Repositories.AuditLog),
+		services.NewOutboxPrivacyModule(c.Repositories.Outbox),
+	)
+
 	c.Services = &Services{
-		User: services.NewUserService(c.Repositories.User),
+		User: userService,
+		AuditLog: services.NewAuditLogService(c.Repositories.AuditLog,
+			time.Duration(c.config.Audit.RetentionDays)*24*time.Hour),
+		Privacy: services.NewPrivacyService(c.Repositories.Privacy, c.Repositories.Transactor, privacyRegistry,
+			services.PrivacyConfig{
+				ExportTTL:          time.Duration(c.config.Privacy.ExportTTLHours) * time.Hour,
+				ErasureGracePeriod: time.Duration(c.config.Privacy.ErasureGraceDays) * 24 * time.Hour,
+				ClaimTimeout:       time.Duration(c.config.Privacy.ClaimTimeoutMinutes) * time.Minute,
+			}),
 	}
 }
 
 // InitControllers 初始化控制器层
 func (c *Container) InitControllers() {
 	c.Controllers = &Controllers{
-		User: controllers.NewUserController(c.Services.User),
+		User:     controllers.NewUserController(c.Services.User),
+		AuditLog: controllers.NewAuditLogController(c.Services.AuditLog),
+		Privacy:  controllers.NewPrivacyController(c.Services.Privacy),
+		Health:   controllers.NewHealthController(c.Health),
 	}
 }
 
@@ -65,3 +290,41 @@ func (c *Container) InitControllers() {
 func (c *Container) GetUserController() *controllers.UserController {
 	return c.Controllers.User
 }
+
+// GetAuditLogController 获取审计日志控制器
+func (c *Container) GetAuditLogController() *controllers.AuditLogController {
+	return c.Controllers.AuditLog
+}
+
+// GetPrivacyController 获取用户隐私数据控制器
+func (c *Container) GetPrivacyController() *controllers.PrivacyController {
+	return c.Controllers.Privacy
+}
+
+// GetHealthController 获取健康检查控制器
+func (c *Container) GetHealthController() *controllers.HealthController {
+	return c.Controllers.Health
+}
+
+// RateLimitMiddleware 创建使用指定策略的限流中间件
+func (c *Container) RateLimitMiddleware(policy string, keyFunc middlewares.RateLimitKeyFunc) gin.HandlerFunc {
+	return middlewares.RateLimitPolicy(c.RateLimit.Limiter, c.RateLimit.Policies[policy], keyFunc)
+}
+
+// AdminMiddleware 创建要求管理员权限的中间件
+func (c *Container) AdminMiddleware() gin.HandlerFunc {
+	return middlewares.AdminRequired(c.Services.User.IsAdmin)
+}
+
+// IdempotencyMiddleware 创建幂等中间件
+// 处理中记录的有效期不短于最长的接口处理超时时间，避免请求仍在处理时记录过期被重复执行
+func (c *Container) IdempotencyMiddleware() gin.HandlerFunc {
+	lockSeconds := max(c.config.Idempotency.LockSeconds,
+		c.config.Request.TimeoutSeconds+idempotencyLockMarginSeconds,
+		c.config.Request.LongTimeoutSeconds+idempotencyLockMarginSeconds)
+
+	return middlewares.Idempotency(c.Idempotency, middlewares.IdempotencyConfig{
+		TTL:     time.Duration(c.config.Idempotency.TTLHours) * time.Hour,
+		LockTTL: time.Duration(lockSeconds) * time.Second,
+	})
+}
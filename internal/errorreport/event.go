@@ -0,0 +1,147 @@
+package errorreport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// 事件级别.
+const (
+	LevelFatal = "fatal" // panic
+	LevelError = "error" // 服务端错误
+)
+
+// maxStackDepth 采集调用栈的最大深度.
+const maxStackDepth = 64
+
+// maxFingerprintFrames 计算指纹时使用的应用代码帧数.
+const maxFingerprintFrames = 10
+
+// Event 错误事件.
+type Event struct {
+	ID          string    `json:"id"`
+	Timestamp   time.Time `json:"timestamp"`
+	Level       string    `json:"level"`
+	Type        string    `json:"type"`                  // 错误类型，如 *errors.errorString、runtime.boundsError
+	Message     string    `json:"message"`               // 错误信息，已脱敏
+	Fingerprint string    `json:"fingerprint"`           // 分组指纹，相同指纹的事件视为同一问题
+	Occurrences int       `json:"occurrences"`           // 自上次上报以来发生的次数，包含本次
+	Stack       []Frame   `json:"stack,omitempty"`       // 调用栈，从发生位置开始
+	Request     *Request  `json:"request,omitempty"`     // 请求上下文
+	Environment string    `json:"environment,omitempty"` // 部署环境
+	Release     string    `json:"release,omitempty"`     // 版本号
+	ServerName  string    `json:"server_name,omitempty"` // 主机名
+}
+
+// Frame 调用栈帧.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	InApp    bool   `json:"in_app"` // 是否为本项目的代码
+}
+
+// Request 事件发生时的请求上下文，请求头和查询参数须已脱敏.
+type Request struct {
+	Method    string            `json:"method"`
+	Route     string            `json:"route,omitempty"` // 路由模板
+	Path      string            `json:"path"`
+	Query     string            `json:"query,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Status    int               `json:"status,omitempty"`
+	ClientIP  string            `json:"client_ip,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	UserID    uint              `json:"user_id,omitempty"`
+	TraceID   string            `json:"trace_id,omitempty"`
+}
+
+// modulePath 本项目的模块路径，用于区分应用代码和依赖库.
+var modulePath = func() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path != "" {
+		return info.Main.Path
+	}
+	return "gitee.com/NextEraAbyss/gin-template"
+}()
+
+// panicStack 在 recover 所在的延迟函数中获取panic发生位置的调用栈.
+// 跳过 runtime.gopanic 及之前的帧，以及紧随其后的运行时帧（如空指针、越界检查）.
+func panicStack() []Frame {
+	pcs := make([]uintptr, maxStackDepth)
+	frames := collectFrames(pcs[:runtime.Callers(2, pcs)])
+
+	for i, frame := range frames {
+		if frame.Function != "runtime.gopanic" {
+			continue
+		}
+		frames = frames[i+1:]
+		for len(frames) > 1 && strings.HasPrefix(frames[0].Function, "runtime.") {
+			frames = frames[1:]
+		}
+		break
+	}
+	return frames
+}
+
+// collectFrames 将程序计数器解析为调用栈帧.
+func collectFrames(pcs []uintptr) []Frame {
+	frames := make([]Frame, 0, len(pcs))
+	iter := runtime.CallersFrames(pcs)
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+			InApp:    strings.HasPrefix(frame.Function, modulePath+"/") || strings.HasPrefix(frame.Function, "main."),
+		})
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// stackFingerprint 根据错误类型和应用代码帧的函数名计算指纹.
+// 不使用行号，代码修改后同一问题仍归为一组；没有应用代码帧时使用全部帧.
+func stackFingerprint(errType string, frames []Frame) string {
+	parts := []string{errType}
+	for _, frame := range frames {
+		if frame.InApp {
+			parts = append(parts, frame.Function)
+		}
+		if len(parts) > maxFingerprintFrames {
+			break
+		}
+	}
+	if len(parts) == 1 {
+		for _, frame := range frames[:min(len(frames), maxFingerprintFrames)] {
+			parts = append(parts, frame.Function)
+		}
+	}
+	return hash(parts...)
+}
+
+// variablePart 匹配错误信息中的引号内容和数字，如 Duplicate entry 'bob' 中的 bob.
+var variablePart = regexp.MustCompile(`'[^']*'|"[^"]*"|\d+`)
+
+// errorFingerprint 根据错误类型、路由和去除变量后的错误信息计算指纹.
+func errorFingerprint(errType, method, route, message string) string {
+	return hash(errType, method, route, variablePart.ReplaceAllString(message, "?"))
+}
+
+// hash 计算分组指纹.
+func hash(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// errorType 获取错误或panic值的类型名称.
+func errorType(v interface{}) string {
+	return fmt.Sprintf("%T", v)
+}
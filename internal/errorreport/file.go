@@ -0,0 +1,42 @@
+package errorreport
+
+import (
+	"context"
+	"encoding/json"
+
+	"gitee.com/NextEraAbyss/gin-template/utils"
+)
+
+// FileTransport 将事件以JSON Lines格式写入本地文件，每个事件一行，文件按日志文件的方式切分.
+type FileTransport struct {
+	file *utils.RotatingFile
+}
+
+// NewFileTransport 创建写入本地文件的发送方式.
+func NewFileTransport(config utils.RotateConfig) (*FileTransport, error) {
+	file, err := utils.NewRotatingFile(config)
+	if err != nil {
+		return nil, err
+	}
+	return &FileTransport{file: file}, nil
+}
+
+// Name 名称.
+func (t *FileTransport) Name() string {
+	return "file"
+}
+
+// Send 写入事件.
+func (t *FileTransport) Send(_ context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = t.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close 关闭文件.
+func (t *FileTransport) Close() error {
+	return t.file.Close()
+}
@@ -0,0 +1,357 @@
+package errorreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/redact"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"github.com/google/uuid"
+)
+
+// 默认配置.
+const (
+	defaultQueueSize   = 256
+	defaultWindow      = time.Minute
+	defaultSendTimeout = 10 * time.Second
+	maxGroups          = 10000
+)
+
+// 发送方式名称.
+const (
+	TransportSentry = "sentry" // Sentry envelope接口
+	TransportFile   = "file"   // 本地JSON Lines文件
+)
+
+// Transport 错误事件的发送方式.
+type Transport interface {
+	// Name 名称，用于日志
+	Name() string
+	// Send 发送事件
+	Send(ctx context.Context, event *Event) error
+	// Close 关闭并释放资源
+	Close() error
+}
+
+// NewTransports 根据名称创建发送方式，出错时关闭已创建的发送方式.
+func NewTransports(names []string, sentryDSN string, file utils.RotateConfig) ([]Transport, error) {
+	var transports []Transport
+	for _, name := range names {
+		var (
+			transport Transport
+			err       error
+		)
+		switch name {
+		case TransportSentry:
+			transport, err = NewSentryTransport(sentryDSN)
+		case TransportFile:
+			transport, err = NewFileTransport(file)
+		default:
+			err = fmt.Errorf("不支持的错误上报发送方式: %s", name)
+		}
+		if err != nil {
+			for _, created := range transports {
+				_ = created.Close()
+			}
+			return nil, err
+		}
+		transports = append(transports, transport)
+	}
+	return transports, nil
+}
+
+// Config 错误上报配置.
+type Config struct {
+	Transports  []Transport
+	Environment string  // 部署环境
+	Release     string  // 版本号
+	SampleRate  float64 // 重复事件的采样比例（0-1），每个分组首次出现的事件总是上报
+	GroupLimit  int     // 每个分组在时间窗口内最多上报的事件数，0 表示不限制
+	GlobalLimit int     // 所有分组在时间窗口内最多上报的事件数，0 表示不限制
+	Window      time.Duration
+	QueueSize   int // 待发送事件的缓冲数量，已满时丢弃新事件
+}
+
+// Stats 上报统计.
+type Stats struct {
+	Sent        uint64 // 发送成功的次数，按发送方式分别计数
+	Failed      uint64 // 发送失败的次数，按发送方式分别计数
+	SampledOut  uint64 // 未被采样的事件数
+	RateLimited uint64 // 超过限额的事件数
+	Dropped     uint64 // 缓冲已满丢弃的事件数
+}
+
+// Reporter 错误上报器.
+// 按指纹对事件分组，经过采样和限流后放入缓冲队列，由后台协程异步发送，不阻塞请求.
+// 未上报的事件计入同组下一次上报事件的 Occurrences.
+type Reporter struct {
+	config     Config
+	serverName string
+
+	mu          sync.Mutex
+	groups      map[string]*group
+	windowStart time.Time
+	windowCount int
+
+	queueMu sync.RWMutex
+	queue   chan *Event
+	closed  bool
+	done    chan struct{}
+
+	sent, failed, sampledOut, rateLimited, dropped atomic.Uint64
+}
+
+// group 事件分组的限流状态.
+type group struct {
+	windowStart time.Time
+	reported    int // 当前窗口内已上报的事件数
+	pending     int // 自上次上报以来未上报的事件数
+	lastSeen    time.Time
+}
+
+// defaultReporter 默认上报器.
+var defaultReporter atomic.Pointer[Reporter]
+
+func init() {
+	defaultReporter.Store(New(Config{}))
+}
+
+// New 创建错误上报器，没有发送方式时不上报.
+func New(config Config) *Reporter {
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		config.SampleRate = 1
+	}
+	if config.Window <= 0 {
+		config.Window = defaultWindow
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultQueueSize
+	}
+
+	r := &Reporter{
+		config: config,
+		groups: make(map[string]*group),
+		done:   make(chan struct{}),
+	}
+	r.serverName, _ = os.Hostname()
+
+	if len(config.Transports) == 0 {
+		close(r.done)
+		return r
+	}
+	r.queue = make(chan *Event, config.QueueSize)
+	go r.run()
+	return r
+}
+
+// SetDefault 设置默认上报器.
+func SetDefault(r *Reporter) {
+	defaultReporter.Store(r)
+}
+
+// Default 获取默认上报器.
+func Default() *Reporter {
+	return defaultReporter.Load()
+}
+
+// Enabled 是否配置了发送方式.
+func (r *Reporter) Enabled() bool {
+	return len(r.config.Transports) > 0
+}
+
+// CapturePanic 上报panic，须在 recover 所在的延迟函数中调用，以获取panic发生位置的调用栈.
+func (r *Reporter) CapturePanic(recovered interface{}, req *Request) {
+	if !r.Enabled() {
+		return
+	}
+
+	stack := panicStack()
+	errType := errorType(recovered)
+	if err, ok := recovered.(error); ok {
+		errType = errorType(err)
+	}
+	r.capture(&Event{
+		Level:       LevelFatal,
+		Type:        errType,
+		Message:     redact.String(fmt.Sprint(recovered)),
+		Fingerprint: stackFingerprint(errType, stack),
+		Stack:       stack,
+		Request:     req,
+	})
+}
+
+// CaptureError 上报服务端错误.
+// 错误通常已在调用链中被处理，没有发生位置的调用栈，按错误类型、路由和错误信息分组.
+func (r *Reporter) CaptureError(err error, req *Request) {
+	if !r.Enabled() || err == nil {
+		return
+	}
+
+	errType := errorType(err)
+	// 使用最内层错误的类型，fmt.Errorf 包装的错误归为同一类型没有区分意义
+	for inner := errors.Unwrap(err); inner != nil; inner = errors.Unwrap(inner) {
+		errType = errorType(inner)
+	}
+
+	var method, route string
+	if req != nil {
+		method, route = req.Method, req.Route
+	}
+	message := redact.String(err.Error())
+	r.capture(&Event{
+		Level:       LevelError,
+		Type:        errType,
+		Message:     message,
+		Fingerprint: errorFingerprint(errType, method, route, message),
+		Request:     req,
+	})
+}
+
+// capture 对事件采样和限流，通过后放入发送队列，事件的错误信息须已脱敏.
+func (r *Reporter) capture(event *Event) {
+	now := time.Now()
+	occurrences, ok := r.admit(event.Fingerprint, now)
+	if !ok {
+		return
+	}
+
+	event.ID = strings.ReplaceAll(uuid.New().String(), "-", "")
+	event.Timestamp = now
+	event.Occurrences = occurrences
+	event.Environment = r.config.Environment
+	event.Release = r.config.Release
+	event.ServerName = r.serverName
+
+	r.queueMu.RLock()
+	defer r.queueMu.RUnlock()
+	if r.closed {
+		r.dropped.Add(1)
+		return
+	}
+	select {
+	case r.queue <- event:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// admit 判断事件是否上报，返回自上次上报以来的次数.
+func (r *Reporter) admit(fingerprint string, now time.Time) (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	g, found := r.groups[fingerprint]
+	if !found {
+		r.pruneGroups(now)
+		g = &group{windowStart: now}
+		r.groups[fingerprint] = g
+	}
+	if now.Sub(g.windowStart) >= r.config.Window {
+		g.windowStart, g.reported = now, 0
+	}
+	g.lastSeen = now
+	g.pending++
+
+	// 新分组总是上报，保证新问题不会因采样而遗漏
+	if found && r.config.SampleRate < 1 && rand.Float64() >= r.config.SampleRate {
+		r.sampledOut.Add(1)
+		return 0, false
+	}
+
+	if now.Sub(r.windowStart) >= r.config.Window {
+		r.windowStart, r.windowCount = now, 0
+	}
+	if (r.config.GroupLimit > 0 && g.reported >= r.config.GroupLimit) ||
+		(r.config.GlobalLimit > 0 && r.windowCount >= r.config.GlobalLimit) {
+		r.rateLimited.Add(1)
+		return 0, false
+	}
+
+	g.reported++
+	r.windowCount++
+	occurrences := g.pending
+	g.pending = 0
+	return occurrences, true
+}
+
+// pruneGroups 分组过多时删除时间窗口内未再出现的分组，仍然过多时全部清空.
+func (r *Reporter) pruneGroups(now time.Time) {
+	if len(r.groups) < maxGroups {
+		return
+	}
+	for fingerprint, g := range r.groups {
+		if now.Sub(g.lastSeen) >= r.config.Window {
+			delete(r.groups, fingerprint)
+		}
+	}
+	if len(r.groups) >= maxGroups {
+		clear(r.groups)
+	}
+}
+
+// run 发送队列中的事件.
+func (r *Reporter) run() {
+	defer close(r.done)
+	for event := range r.queue {
+		for _, transport := range r.config.Transports {
+			ctx, cancel := context.WithTimeout(context.Background(), defaultSendTimeout)
+			err := transport.Send(ctx, event)
+			cancel()
+			if err != nil {
+				r.failed.Add(1)
+				// 服务端限流期间不逐条记录日志
+				if errors.Is(err, ErrSentryRateLimited) {
+					continue
+				}
+				utils.Warnf("错误上报发送失败: %s: %v", transport.Name(), err)
+				continue
+			}
+			r.sent.Add(1)
+		}
+	}
+}
+
+// Stats 获取上报统计.
+func (r *Reporter) Stats() Stats {
+	return Stats{
+		Sent:        r.sent.Load(),
+		Failed:      r.failed.Load(),
+		SampledOut:  r.sampledOut.Load(),
+		RateLimited: r.rateLimited.Load(),
+		Dropped:     r.dropped.Load(),
+	}
+}
+
+// Close 发送完已缓冲的事件后关闭发送方式，ctx 结束时不再等待.
+func (r *Reporter) Close(ctx context.Context) error {
+	r.queueMu.Lock()
+	if r.closed || r.queue == nil {
+		r.closed = true
+		r.queueMu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.queue)
+	r.queueMu.Unlock()
+
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return fmt.Errorf("等待错误上报发送完成超时: %w", ctx.Err())
+	}
+
+	var errs []error
+	for _, transport := range r.config.Transports {
+		if err := transport.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", transport.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
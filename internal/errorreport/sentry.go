@@ -0,0 +1,224 @@
+package errorreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// sentryClient 上报时使用的客户端标识.
+const sentryClient = "gin-template/1.0"
+
+// defaultRetryAfter 服务端限流且未返回 Retry-After 时暂停发送的时长.
+const defaultRetryAfter = time.Minute
+
+// ErrSentryRateLimited 服务端限流期间不发送事件.
+var ErrSentryRateLimited = errors.New("错误上报服务限流中")
+
+// SentryTransport 以Sentry envelope格式发送事件，兼容Sentry及GlitchTip等实现了envelope接口的服务.
+type SentryTransport struct {
+	dsn       string
+	endpoint  string
+	publicKey string
+	client    *http.Client
+
+	disabledUntil atomic.Int64 // 服务端限流的截止时间（UnixNano）
+}
+
+// NewSentryTransport 根据DSN创建发送方式，DSN格式为 https://公钥@主机/项目ID.
+func NewSentryTransport(dsn string) (*SentryTransport, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("无效的Sentry DSN: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("无效的Sentry DSN协议: %s", u.Scheme)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, errors.New("Sentry DSN缺少公钥")
+	}
+
+	// 项目ID为路径的最后一段，之前的部分为服务的路径前缀
+	path := strings.TrimSuffix(u.Path, "/")
+	index := strings.LastIndex(path, "/")
+	projectID := path[index+1:]
+	if projectID == "" {
+		return nil, errors.New("Sentry DSN缺少项目ID")
+	}
+
+	endpoint := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   path[:index] + "/api/" + projectID + "/envelope/",
+	}
+	return &SentryTransport{
+		dsn:       dsn,
+		endpoint:  endpoint.String(),
+		publicKey: u.User.Username(),
+		client:    &http.Client{Timeout: defaultSendTimeout},
+	}, nil
+}
+
+// Name 名称.
+func (t *SentryTransport) Name() string {
+	return "sentry"
+}
+
+// Send 发送事件，服务端限流期间直接返回 ErrSentryRateLimited.
+func (t *SentryTransport) Send(ctx context.Context, event *Event) error {
+	if time.Now().UnixNano() < t.disabledUntil.Load() {
+		return ErrSentryRateLimited
+	}
+
+	body, err := t.envelope(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", sentryClient, t.publicKey))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		t.disabledUntil.Store(time.Now().Add(retryAfter(resp.Header.Get("Retry-After"))).UnixNano())
+		return ErrSentryRateLimited
+	case resp.StatusCode >= http.StatusBadRequest:
+		return fmt.Errorf("错误上报服务返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close 关闭空闲连接.
+func (t *SentryTransport) Close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+// envelope 构造envelope：envelope头、事件项头和事件各占一行.
+func (t *SentryTransport) envelope(event *Event) ([]byte, error) {
+	payload, err := json.Marshal(sentryEvent(event))
+	if err != nil {
+		return nil, err
+	}
+	header, err := json.Marshal(map[string]interface{}{
+		"event_id": event.ID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		"dsn":      t.dsn,
+	})
+	if err != nil {
+		return nil, err
+	}
+	itemHeader, err := json.Marshal(map[string]interface{}{
+		"type":   "event",
+		"length": len(payload),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, line := range [][]byte{header, itemHeader, payload} {
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// sentryEvent 将事件转换为Sentry事件格式.
+func sentryEvent(event *Event) map[string]interface{} {
+	// Sentry的调用栈从最外层开始
+	frames := make([]map[string]interface{}, 0, len(event.Stack))
+	for i := len(event.Stack) - 1; i >= 0; i-- {
+		frame := event.Stack[i]
+		frames = append(frames, map[string]interface{}{
+			"function": frame.Function,
+			"module":   functionModule(frame.Function),
+			"abs_path": frame.File,
+			"lineno":   frame.Line,
+			"in_app":   frame.InApp,
+		})
+	}
+
+	exception := map[string]interface{}{
+		"type":  event.Type,
+		"value": event.Message,
+	}
+	if len(frames) > 0 {
+		exception["stacktrace"] = map[string]interface{}{"frames": frames}
+	}
+
+	result := map[string]interface{}{
+		"event_id":    event.ID,
+		"timestamp":   event.Timestamp.UTC().Format(time.RFC3339Nano),
+		"level":       event.Level,
+		"platform":    "go",
+		"environment": event.Environment,
+		"release":     event.Release,
+		"server_name": event.ServerName,
+		"fingerprint": []string{event.Fingerprint},
+		"exception":   map[string]interface{}{"values": []interface{}{exception}},
+		"extra":       map[string]interface{}{"occurrences": event.Occurrences},
+	}
+
+	if req := event.Request; req != nil {
+		result["transaction"] = req.Method + " " + req.Route
+		result["request"] = map[string]interface{}{
+			"method":       req.Method,
+			"url":          req.Path,
+			"query_string": req.Query,
+			"headers":      req.Headers,
+		}
+		result["tags"] = map[string]string{
+			"route":       req.Route,
+			"status_code": strconv.Itoa(req.Status),
+			"request_id":  req.RequestID,
+		}
+		if req.UserID != 0 {
+			result["user"] = map[string]string{"id": strconv.FormatUint(uint64(req.UserID), 10)}
+		}
+		if req.TraceID != "" {
+			result["contexts"] = map[string]interface{}{"trace": map[string]string{"trace_id": req.TraceID}}
+		}
+	}
+	return result
+}
+
+// functionModule 获取函数所在的包路径.
+func functionModule(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// retryAfter 解析 Retry-After 响应头，支持秒数和HTTP日期.
+func retryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && time.Until(t) > 0 {
+		return time.Until(t)
+	}
+	return defaultRetryAfter
+}
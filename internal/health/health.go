@@ -0,0 +1,210 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Probe 探针类型.
+type Probe string
+
+// 探针类型.
+const (
+	Liveness  Probe = "livez"    // 存活探针，失败时进程应被重启，不应包含外部依赖
+	Readiness Probe = "readyz"   // 就绪探针，失败时不再接收流量
+	Startup   Probe = "startupz" // 启动探针，全部通过一次后不再执行检查
+)
+
+// 检查状态.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// ErrShuttingDown 服务正在关闭.
+var ErrShuttingDown = errors.New("服务正在关闭")
+
+// shutdownCheckName 关闭状态在就绪探针结果中的检查名称.
+const shutdownCheckName = "shutdown"
+
+// CheckFunc 健康检查函数，返回 nil 表示健康.
+type CheckFunc func(ctx context.Context) error
+
+// Check 命名的健康检查.
+type Check struct {
+	Name     string        // 检查名称
+	Check    CheckFunc     // 检查函数
+	Timeout  time.Duration // 单次检查超时时间，默认2秒
+	CacheTTL time.Duration // 检查结果的缓存时间，避免频繁的探测请求压垮依赖，默认2秒
+	Probes   []Probe       // 参与的探针
+}
+
+// CheckResult 单项检查结果.
+type CheckResult struct {
+	Status     string    `json:"status"`          // 检查状态：ok 或 fail
+	Error      string    `json:"error,omitempty"` // 失败原因
+	DurationMs float64   `json:"duration_ms"`     // 检查耗时（毫秒）
+	CheckedAt  time.Time `json:"checked_at"`      // 检查时间，结果可能来自缓存
+}
+
+// Report 探针结果.
+type Report struct {
+	Status string                 `json:"status"` // 整体状态，任一检查失败时为 fail
+	Checks map[string]CheckResult `json:"checks"` // 各项检查结果
+}
+
+// Healthy 是否健康.
+func (r *Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// Registry 健康检查注册表.
+type Registry struct {
+	mu           sync.RWMutex
+	checks       []*registeredCheck
+	shuttingDown atomic.Bool
+	started      atomic.Bool
+}
+
+// registeredCheck 带结果缓存的检查.
+type registeredCheck struct {
+	Check
+	mu      sync.Mutex
+	result  CheckResult
+	expires time.Time
+}
+
+// DefaultRegistry 默认健康检查注册表.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry 创建健康检查注册表.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register 注册健康检查.
+func (r *Registry) Register(check Check) {
+	if check.Timeout <= 0 {
+		check.Timeout = 2 * time.Second
+	}
+	if check.CacheTTL <= 0 {
+		check.CacheTTL = 2 * time.Second
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, &registeredCheck{Check: check})
+}
+
+// SetShuttingDown 标记服务正在关闭，之后就绪探针始终失败.
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Run 并行执行探针包含的检查.
+func (r *Registry) Run(ctx context.Context, probe Probe) *Report {
+	report := &Report{Status: StatusOK, Checks: make(map[string]CheckResult)}
+
+	if probe == Readiness && r.shuttingDown.Load() {
+		report.Status = StatusFail
+		report.Checks[shutdownCheckName] = CheckResult{
+			Status:    StatusFail,
+			Error:     ErrShuttingDown.Error(),
+			CheckedAt: time.Now(),
+		}
+		return report
+	}
+
+	// 启动完成后启动探针不再检查依赖，避免依赖短暂故障导致重启
+	if probe == Startup && r.started.Load() {
+		return report
+	}
+
+	r.mu.RLock()
+	var checks []*registeredCheck
+	for _, check := range r.checks {
+		if check.includes(probe) {
+			checks = append(checks, check)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check *registeredCheck) {
+			defer wg.Done()
+			results[i] = check.run(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for i, check := range checks {
+		report.Checks[check.Name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	if probe == Startup && report.Healthy() {
+		r.started.Store(true)
+	}
+
+	return report
+}
+
+// includes 判断检查是否参与指定探针.
+func (c *registeredCheck) includes(probe Probe) bool {
+	for _, p := range c.Probes {
+		if p == probe {
+			return true
+		}
+	}
+	return false
+}
+
+// run 执行检查，缓存有效期内直接返回上次的结果.
+func (c *registeredCheck) run(ctx context.Context) CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(c.expires) {
+		return c.result
+	}
+
+	// 结果会被缓存，不受单个探测请求取消的影响
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.Timeout)
+	defer cancel()
+
+	// 检查函数未响应上下文取消时也按超时返回
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{
+		Status:     StatusOK,
+		DurationMs: float64(time.Since(now).Microseconds()) / 1000,
+		CheckedAt:  now,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	c.result = result
+	c.expires = now.Add(c.CacheTTL)
+	return result
+}
@@ -0,0 +1,230 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix 幂等记录在存储中的键前缀.
+const keyPrefix = "idempotency:"
+
+// ErrNotOwner 记录已过期并被其他请求重新登记，当前请求不能再修改该记录.
+var ErrNotOwner = errors.New("幂等记录已被其他请求登记")
+
+// completeScript 仅当记录不存在或仍属于当前请求时保存响应.
+var completeScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current then
+	local ok, record = pcall(cjson.decode, current)
+	if not ok or record.token ~= ARGV[1] then
+		return 0
+	end
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+// releaseScript 仅当记录属于当前请求时删除.
+var releaseScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if not current then
+	return 1
+end
+local ok, record = pcall(cjson.decode, current)
+if not ok or record.token ~= ARGV[1] then
+	return 0
+end
+redis.call("DEL", KEYS[1])
+return 1
+`)
+
+// Record 幂等请求记录.
+// 请求处理期间只保存请求指纹和登记令牌，处理完成后保存完整响应.
+type Record struct {
+	Fingerprint string              `json:"fingerprint"`      // 请求指纹，用于识别使用相同键的不同请求
+	Token       string              `json:"token"`            // 登记令牌，只有登记记录的请求可以保存响应或删除记录
+	Completed   bool                `json:"completed"`        // 是否已处理完成
+	Status      int                 `json:"status,omitempty"` // 响应状态码
+	Header      map[string][]string `json:"header,omitempty"` // 响应头
+	Body        []byte              `json:"body,omitempty"`   // 响应内容
+}
+
+// Store 幂等记录存储.
+type Store interface {
+	// Acquire 以 token 登记处理中的请求，成功时返回 true；键已存在时返回已有记录和 false.
+	// lockTTL 为处理中记录的有效期，处理请求的实例异常退出后记录在该时间后过期.
+	Acquire(ctx context.Context, key, fingerprint, token string, lockTTL time.Duration) (*Record, bool, error)
+	// Complete 保存请求的响应，记录已被其他令牌登记时返回 ErrNotOwner.
+	Complete(ctx context.Context, key, token string, record *Record, ttl time.Duration) error
+	// Release 删除记录，允许客户端使用相同的键重试；记录已被其他令牌登记时返回 ErrNotOwner.
+	Release(ctx context.Context, key, token string) error
+}
+
+// RedisStore 基于Redis的幂等记录存储，多实例共享.
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore 创建Redis幂等记录存储.
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Acquire 登记处理中的请求.
+func (s *RedisStore) Acquire(ctx context.Context, key, fingerprint, token string, lockTTL time.Duration) (*Record, bool, error) {
+	data, err := json.Marshal(&Record{Fingerprint: fingerprint, Token: token})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// 已有记录可能在SETNX和GET之间过期，此时重新登记
+	for attempt := 0; attempt < 2; attempt++ {
+		ok, err := s.client.SetNX(ctx, keyPrefix+key, data, lockTTL).Result()
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			return nil, true, nil
+		}
+
+		existing, err := s.client.Get(ctx, keyPrefix+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		var record Record
+		if err := json.Unmarshal(existing, &record); err != nil {
+			return nil, false, err
+		}
+		return &record, false, nil
+	}
+
+	// 记录反复过期说明有其他请求正在竞争同一个键，按处理中返回
+	return &Record{Fingerprint: fingerprint}, false, nil
+}
+
+// Complete 保存请求的响应.
+func (s *RedisStore) Complete(ctx context.Context, key, token string, record *Record, ttl time.Duration) error {
+	saved := *record
+	saved.Token = token
+	data, err := json.Marshal(&saved)
+	if err != nil {
+		return err
+	}
+
+	ok, err := completeScript.Run(ctx, s.client, []string{keyPrefix + key}, token, data, ttl.Milliseconds()).Bool()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// Release 删除记录.
+func (s *RedisStore) Release(ctx context.Context, key, token string) error {
+	ok, err := releaseScript.Run(ctx, s.client, []string{keyPrefix + key}, token).Bool()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// MemoryStore 进程内幂等记录存储，只在当前实例有效，用于未配置Redis的单实例部署.
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]memoryRecord
+	lastSweep time.Time
+}
+
+// memoryRecord 带过期时间的记录.
+type memoryRecord struct {
+	record    Record
+	expiresAt time.Time
+}
+
+// NewMemoryStore 创建进程内幂等记录存储.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]memoryRecord)}
+}
+
+// Acquire 登记处理中的请求.
+func (s *MemoryStore) Acquire(ctx context.Context, key, fingerprint, token string, lockTTL time.Duration) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		s.deleteExpired(now)
+		s.lastSweep = now
+	}
+
+	if existing, ok := s.records[key]; ok && now.Before(existing.expiresAt) {
+		record := existing.record
+		return &record, false, nil
+	}
+
+	s.records[key] = memoryRecord{
+		record:    Record{Fingerprint: fingerprint, Token: token},
+		expiresAt: now.Add(lockTTL),
+	}
+	return nil, true, nil
+}
+
+// Complete 保存请求的响应.
+func (s *MemoryStore) Complete(ctx context.Context, key, token string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if existing, ok := s.records[key]; ok && now.Before(existing.expiresAt) && existing.record.Token != token {
+		return ErrNotOwner
+	}
+
+	saved := *record
+	saved.Token = token
+	s.records[key] = memoryRecord{
+		record:    saved,
+		expiresAt: now.Add(ttl),
+	}
+	return nil
+}
+
+// Release 删除记录.
+func (s *MemoryStore) Release(ctx context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.records[key]
+	if !ok || !time.Now().Before(existing.expiresAt) {
+		delete(s.records, key)
+		return nil
+	}
+	if existing.record.Token != token {
+		return ErrNotOwner
+	}
+
+	delete(s.records, key)
+	return nil
+}
+
+// deleteExpired 删除过期记录，调用方需持有锁.
+func (s *MemoryStore) deleteExpired(now time.Time) {
+	for key, record := range s.records {
+		if now.After(record.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/cache"
+)
+
+// instrumentedCache 统计命中情况的缓存装饰器.
+type instrumentedCache struct {
+	cache.Cache
+	hits   func(n int)
+	misses func(n int)
+	errors func()
+}
+
+// InstrumentCache 包装缓存，按 name 统计 Get、GetOrLoad 和 MGet 的命中和未命中次数.
+func InstrumentCache(name string, c cache.Cache) cache.Cache {
+	hit := CacheRequestsTotal.WithLabelValues(name, "hit")
+	miss := CacheRequestsTotal.WithLabelValues(name, "miss")
+	failed := CacheRequestsTotal.WithLabelValues(name, "error")
+
+	return &instrumentedCache{
+		Cache:  c,
+		hits:   func(n int) { hit.Add(float64(n)) },
+		misses: func(n int) { miss.Add(float64(n)) },
+		errors: failed.Inc,
+	}
+}
+
+// Get 获取缓存.
+func (c *instrumentedCache) Get(ctx context.Context, key string, value interface{}) error {
+	err := c.Cache.Get(ctx, key, value)
+	c.observe(err)
+	return err
+}
+
+// GetOrLoad 获取缓存，不存在时加载.
+// 加载函数被调用即为未命中，否则为命中；合并加载时等待其他请求加载结果的调用计为命中.
+func (c *instrumentedCache) GetOrLoad(ctx context.Context, key string, value interface{}, expiration time.Duration, loader cache.Loader) error {
+	loaded := false
+	err := c.Cache.GetOrLoad(ctx, key, value, expiration, func(ctx context.Context) (interface{}, error) {
+		loaded = true
+		return loader(ctx)
+	})
+
+	switch {
+	case loaded:
+		c.misses(1)
+	case err == nil:
+		c.hits(1)
+	default:
+		c.errors()
+	}
+	return err
+}
+
+// MGet 批量获取缓存.
+func (c *instrumentedCache) MGet(ctx context.Context, values map[string]interface{}) ([]string, error) {
+	missing, err := c.Cache.MGet(ctx, values)
+	if err != nil {
+		c.errors()
+		return missing, err
+	}
+
+	c.hits(len(values) - len(missing))
+	c.misses(len(missing))
+	return missing, nil
+}
+
+// observe 记录单次读取结果.
+func (c *instrumentedCache) observe(err error) {
+	switch {
+	case err == nil:
+		c.hits(1)
+	case errors.Is(err, cache.ErrCacheMiss):
+		c.misses(1)
+	default:
+		c.errors()
+	}
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"gitee.com/NextEraAbyss/gin-template/internal/errorreport"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// errorReportCollector 采集错误上报统计信息.
+type errorReportCollector struct {
+	events *prometheus.Desc
+}
+
+// newErrorReportCollector 创建错误上报指标采集器.
+func newErrorReportCollector() *errorReportCollector {
+	return &errorReportCollector{
+		events: prometheus.NewDesc("error_reports_total", "错误上报事件数，按处理结果统计", []string{"result"}, nil),
+	}
+}
+
+// Describe 输出指标描述.
+func (c *errorReportCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.events
+}
+
+// Collect 采集默认上报器的统计信息.
+func (c *errorReportCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := errorreport.Default().Stats()
+	ch <- prometheus.MustNewConstMetric(c.events, prometheus.CounterValue, float64(stats.Sent), "sent")
+	ch <- prometheus.MustNewConstMetric(c.events, prometheus.CounterValue, float64(stats.Failed), "failed")
+	ch <- prometheus.MustNewConstMetric(c.events, prometheus.CounterValue, float64(stats.SampledOut), "sampled_out")
+	ch <- prometheus.MustNewConstMetric(c.events, prometheus.CounterValue, float64(stats.RateLimited), "rate_limited")
+	ch <- prometheus.MustNewConstMetric(c.events, prometheus.CounterValue, float64(stats.Dropped), "dropped")
+}
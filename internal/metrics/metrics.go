@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+)
+
+// Registry 本服务的指标注册表，包含Go运行时和进程指标.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestsTotal HTTP请求数，按请求方法、路由模板和状态码统计.
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP请求数",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration HTTP请求处理耗时（秒）.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP请求处理耗时（秒）",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestsInFlight 正在处理的HTTP请求数.
+	HTTPRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "正在处理的HTTP请求数",
+	})
+
+	// CacheRequestsTotal 缓存读取次数，result 为 hit、miss 或 error.
+	CacheRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_requests_total",
+		Help: "缓存读取次数",
+	}, []string{"cache", "result"})
+
+	// RateLimitRejectedTotal 被限流拒绝的请求数，按限流策略统计.
+	RateLimitRejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ratelimit_rejected_total",
+		Help: "被限流拒绝的请求数",
+	}, []string{"policy"})
+
+	// LogDroppedTotal 异步写入缓冲已满或写入失败时丢弃的日志条数.
+	LogDroppedTotal = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "log_dropped_total",
+		Help: "丢弃的日志条数",
+	}, func() float64 {
+		return float64(utils.DroppedLogs())
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		HTTPRequestsInFlight,
+		CacheRequestsTotal,
+		RateLimitRejectedTotal,
+		LogDroppedTotal,
+		newErrorReportCollector(),
+	)
+}
+
+// Handler 返回输出指标的HTTP处理器.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDBStats 注册数据库连接池指标，name 用于区分多个数据库.
+func RegisterDBStats(name string, db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterRedisPool 注册Redis连接池指标.
+func RegisterRedisPool(client *redis.Client) error {
+	return Registry.Register(newRedisPoolCollector(client))
+}
@@ -2,12 +2,13 @@ package mysql
 
 import (
 	"fmt"
-	"log"
-	"os"
 	"time"
 
 	"gitee.com/NextEraAbyss/gin-template/config"
+	"gitee.com/NextEraAbyss/gin-template/internal/audit"
+	"gitee.com/NextEraAbyss/gin-template/internal/tracing"
 	"gitee.com/NextEraAbyss/gin-template/models"
+	"gitee.com/NextEraAbyss/gin-template/utils"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -17,34 +18,48 @@ import (
 var DB *gorm.DB
 
 // InitDB 初始化数据库连接
-func InitDB(config *config.Config) *gorm.DB {
+// 数据库暂不可用时不会退出，连接在首次使用时建立，可用状态由健康检查反映
+func InitDB(cfg *config.Config) *gorm.DB {
 	var db *gorm.DB
 	var err error
 
-	// 设置自定义Logger
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
-		logger.Config{
-			SlowThreshold:             time.Second, // 慢查询阈值
-			LogLevel:                  logger.Info, // 日志级别
-			IgnoreRecordNotFoundError: true,        // 忽略记录未找到错误
-			Colorful:                  true,        // 彩色输出
-		},
-	)
+	// 设置自定义Logger，生产环境只输出慢查询和错误，需要时可通过管理接口临时开启SQL日志
+	logLevel := logger.Info
+	if cfg.Env == config.EnvProduction {
+		logLevel = logger.Warn
+	}
+	sqlLog = newSQLLogger(logLevel)
 
 	// MySQL连接
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		config.Database.User,
-		config.Database.Password,
-		config.Database.Host,
-		config.Database.Port,
-		config.Database.Name)
-	db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: newLogger,
-	})
+		cfg.Database.User,
+		cfg.Database.Password,
+		cfg.Database.Host,
+		cfg.Database.Port,
+		cfg.Database.Name)
+	gormConfig := &gorm.Config{
+		Logger:               sqlLog,
+		DisableAutomaticPing: true,
+	}
+	db, err = gorm.Open(mysql.Open(dsn), gormConfig)
+	if err != nil {
+		// 无法连接时跳过服务器版本检测，按默认的MySQL特性创建连接
+		utils.Errorf("数据库连接失败，将在使用时重试: %v", err)
+		db, err = gorm.Open(mysql.New(mysql.Config{DSN: dsn, SkipInitializeWithVersion: true}), gormConfig)
+	}
 
 	if err != nil {
-		panic(fmt.Sprintf("Failed to connect to database: %v", err))
+		panic(fmt.Sprintf("Failed to open database: %v", err))
+	}
+
+	// 注册链路追踪插件，为请求中的数据库操作创建子span
+	if err = db.Use(tracing.NewGormPlugin()); err != nil {
+		panic(fmt.Sprintf("Failed to register tracing plugin: %v", err))
+	}
+
+	// 注册审计插件，自动记录被审计模型的变更
+	if err = db.Use(audit.NewPlugin(&models.User{})); err != nil {
+		panic(fmt.Sprintf("Failed to register audit plugin: %v", err))
 	}
 
 	// 配置连接池
@@ -60,17 +75,6 @@ func InitDB(config *config.Config) *gorm.DB {
 	// SetConnMaxLifetime 设置连接的最大可复用时间
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	// 迁移数据库（开发环境自动迁移）
-	if config.Env != "production" {
-		err = db.AutoMigrate(
-			&models.User{},
-			// 添加其他模型...
-		)
-		if err != nil {
-			log.Printf("数据库迁移失败: %v", err)
-		}
-	}
-
 	// 设置全局变量
 	DB = db
 
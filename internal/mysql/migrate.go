@@ -0,0 +1,68 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gitee.com/NextEraAbyss/gin-template/models"
+	"gorm.io/gorm"
+)
+
+// Models 需要自动迁移的模型
+var Models = []interface{}{
+	&models.User{},              // 用户表
+	&models.AuditLog{},          // 审计日志表
+	&models.OutboxEvent{},       // 发件箱事件表
+	&models.PrivacyRequest{},    // 隐私请求表
+	&models.PrivacyRequestLog{}, // 隐私请求处理记录表
+	&models.PrivacyExport{},     // 数据导出文件表
+	// 添加其他模型...
+}
+
+// Migrate 自动迁移所有模型
+func Migrate(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(Models...)
+}
+
+// CheckMigrations 检查所有模型的数据表和字段是否已创建，即数据库结构是否为最新
+func CheckMigrations(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	migrator := db.Migrator()
+
+	for _, model := range Models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("解析模型失败: %w", err)
+		}
+
+		if !migrator.HasTable(model) {
+			return fmt.Errorf("数据表 %s 不存在，需要执行迁移", stmt.Schema.Table)
+		}
+
+		columnTypes, err := migrator.ColumnTypes(model)
+		if err != nil {
+			return fmt.Errorf("读取数据表 %s 结构失败: %w", stmt.Schema.Table, err)
+		}
+
+		columns := make(map[string]bool, len(columnTypes))
+		for _, columnType := range columnTypes {
+			columns[columnType.Name()] = true
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !columns[field.DBName] {
+				return fmt.Errorf("数据表 %s 缺少字段 %s，需要执行迁移", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Ping 检查数据库连接
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
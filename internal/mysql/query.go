@@ -2,13 +2,17 @@ package mysql
 
 import (
 	"context"
+	"crypto/sha1"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"gitee.com/NextEraAbyss/gin-template/internal/cache"
+	"gitee.com/NextEraAbyss/gin-template/internal/redis"
 	"gitee.com/NextEraAbyss/gin-template/utils"
+	redisClient "github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -57,6 +61,8 @@ type QueryBuilder struct {
 	enableExplain bool
 	// 连接池配置.
 	poolConfig *PoolConfig
+	// 插入冲突时的更新策略.
+	upsert *clause.OnConflict
 }
 
 // PoolConfig 连接池配置.
@@ -218,9 +224,42 @@ func (qb *QueryBuilder) WithPoolConfig(config *PoolConfig) *QueryBuilder {
 	return qb
 }
 
-// getCacheKey 生成缓存键.
-func (qb *QueryBuilder) getCacheKey(operation string, args ...interface{}) string {
-	return fmt.Sprintf("query:%s:%v", operation, args)
+// WithUpsert 设置插入冲突时的处理策略，作用于 Create 和 BatchCreate.
+func (qb *QueryBuilder) WithUpsert(conflict clause.OnConflict) *QueryBuilder {
+	qb.upsert = &conflict
+
+	return qb
+}
+
+// getCacheKey 生成缓存键，键以表名为前缀，便于写操作时按表清理.
+func (qb *QueryBuilder) getCacheKey(model interface{}, operation string, statement string) string {
+	sum := sha1.Sum([]byte(statement))
+
+	return qb.cachePrefix(model) + operation + ":" + hex.EncodeToString(sum[:])
+}
+
+// cachePrefix 获取表的查询缓存键前缀.
+func (qb *QueryBuilder) cachePrefix(model interface{}) string {
+	table := qb.query.Statement.Table
+	if table == "" && model != nil {
+		stmt := &gorm.Statement{DB: qb.db}
+		if err := stmt.Parse(model); err == nil {
+			table = stmt.Schema.Table
+		}
+	}
+
+	return fmt.Sprintf("query:%s:", table)
+}
+
+// invalidateCache 清理表的查询缓存.
+func (qb *QueryBuilder) invalidateCache(model interface{}) {
+	if !qb.enableCache || qb.cache == nil {
+		return
+	}
+
+	if err := qb.cache.DeleteByPrefix(qb.context, qb.cachePrefix(model)); err != nil {
+		utils.Warnf("清理查询缓存失败: %v", err)
+	}
 }
 
 // First 获取第一条记录.
@@ -239,11 +278,20 @@ func (qb *QueryBuilder) First(dest interface{}) error {
 	}
 
 	// 如果启用缓存，尝试从缓存获取.
+	var cacheKey string
 	if qb.enableCache && qb.cache != nil {
-		cacheKey := qb.getCacheKey("First", qb.query.Statement.SQL.String())
-		if err := qb.cache.Get(qb.context, cacheKey, dest); err == nil {
+		statement := qb.query.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.First(dest)
+		})
+		cacheKey = qb.getCacheKey(dest, "First", statement)
+
+		err := qb.cache.Get(qb.context, cacheKey, dest)
+		if err == nil {
 			return nil
 		}
+		if !errors.Is(err, cache.ErrCacheMiss) {
+			utils.Warnf("读取查询缓存失败: %v", err)
+		}
 		// 缓存未命中，继续执行数据库查询.
 	}
 
@@ -259,11 +307,10 @@ func (qb *QueryBuilder) First(dest interface{}) error {
 	}
 
 	// 如果启用缓存，将结果存入缓存.
-	if qb.enableCache && qb.cache != nil {
-		cacheKey := qb.getCacheKey("First", qb.query.Statement.SQL.String())
+	if cacheKey != "" {
 		if err := qb.cache.Set(qb.context, cacheKey, dest, qb.cacheExpiration); err != nil {
 			// 记录缓存错误，但不中断流程.
-			log.Printf("Failed to set cache: %v", err)
+			utils.FromContext(qb.context).Warnf("Failed to set cache: %v", err)
 		}
 	}
 
@@ -296,7 +343,12 @@ func (qb *QueryBuilder) Create(value interface{}) error {
 		utils.Debugf("创建耗时: %v", time.Since(start))
 	}()
 
-	return qb.db.Create(value).Error
+	db := qb.db.WithContext(qb.context)
+	if qb.upsert != nil {
+		db = db.Clauses(*qb.upsert)
+	}
+
+	return db.Create(value).Error
 }
 
 // Updates 更新记录.
@@ -317,22 +369,21 @@ func (qb *QueryBuilder) Updates(attrs interface{}) error {
 	}
 
 	// 如果启用缓存，清除相关缓存.
-	if qb.enableCache && qb.cache != nil {
-		// TODO: 实现缓存清理逻辑.
-		_ = qb.cache // 避免未使用变量警告
-	}
+	qb.invalidateCache(qb.query.Statement.Model)
 
 	return nil
 }
 
 // Delete 删除记录.
 func (qb *QueryBuilder) Delete(value interface{}) error {
-	if qb.enableCache && qb.cache != nil {
-		// TODO: 实现缓存删除逻辑.
-		_ = qb.cache // 避免未使用变量警告
+	if err := qb.db.Delete(value).Error; err != nil {
+		return err
 	}
 
-	return qb.db.Delete(value).Error
+	// 如果启用缓存，清除相关缓存.
+	qb.invalidateCache(value)
+
+	return nil
 }
 
 // Transaction 事务.
@@ -342,7 +393,7 @@ func (qb *QueryBuilder) Transaction(fc func(tx *gorm.DB) error) error {
 		utils.Debugf("事务耗时: %v", time.Since(start))
 	}()
 
-	return qb.db.Transaction(fc)
+	return qb.db.WithContext(qb.context).Transaction(fc)
 }
 
 // Paginate 分页.
@@ -373,6 +424,10 @@ func (qb *QueryBuilder) BatchCreate(values interface{}, batchSize int) error {
 	}()
 
 	return qb.Transaction(func(tx *gorm.DB) error {
+		if qb.upsert != nil {
+			tx = tx.Clauses(*qb.upsert)
+		}
+
 		return tx.CreateInBatches(values, batchSize).Error
 	})
 }
@@ -498,34 +553,53 @@ func (qb *QueryBuilder) GetPoolStats() (*sql.DBStats, error) {
 	return &stats, nil
 }
 
-// MonitorPool 监控连接池
-func (qb *QueryBuilder) MonitorPool() {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for range ticker.C {
-			stats, err := qb.GetPoolStats()
-			if err != nil {
-				utils.Errorf("获取连接池统计信息失败: %v", err)
-				continue
-			}
-
-			// 记录连接池统计信息
-			utils.Debugf("连接池统计 - 打开连接数: %d, 使用中连接数: %d, 空闲连接数: %d, 等待连接数: %d",
-				stats.OpenConnections, stats.InUse, stats.Idle, stats.WaitCount)
-
-			// 检查连接池健康状态
-			if stats.OpenConnections >= qb.poolConfig.MaxOpenConns*8/10 {
-				utils.Warnf("连接池接近最大连接数 - 打开连接数: %d, 最大连接数: %d",
-					stats.OpenConnections, qb.poolConfig.MaxOpenConns)
-			}
-
-			if stats.WaitCount > 0 {
-				utils.Warnf("连接池有等待连接 - 等待连接数: %d", stats.WaitCount)
-			}
+// poolMonitorElection 连接池监控的选主名称.
+const poolMonitorElection = "mysql:pool-monitor"
+
+// MonitorPool 监控连接池，直到 ctx 取消.
+// 传入Redis客户端时通过选主只在一个实例上执行，为空时在当前实例上执行.
+func (qb *QueryBuilder) MonitorPool(ctx context.Context, client *redisClient.Client) {
+	if client == nil {
+		go qb.monitorPool(ctx)
+		return
+	}
+
+	election := redis.NewLeaderElection(client, poolMonitorElection, redis.DefaultElectionConfig())
+	go election.Run(ctx, qb.monitorPool)
+}
+
+// monitorPool 每分钟记录一次连接池统计信息，直到 ctx 取消.
+func (qb *QueryBuilder) monitorPool(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-	}()
+
+		stats, err := qb.GetPoolStats()
+		if err != nil {
+			utils.Errorf("获取连接池统计信息失败: %v", err)
+			continue
+		}
+
+		// 记录连接池统计信息
+		utils.Debugf("连接池统计 - 打开连接数: %d, 使用中连接数: %d, 空闲连接数: %d, 等待连接数: %d",
+			stats.OpenConnections, stats.InUse, stats.Idle, stats.WaitCount)
+
+		// 检查连接池健康状态
+		if stats.OpenConnections >= qb.poolConfig.MaxOpenConns*8/10 {
+			utils.Warnf("连接池接近最大连接数 - 打开连接数: %d, 最大连接数: %d",
+				stats.OpenConnections, qb.poolConfig.MaxOpenConns)
+		}
+
+		if stats.WaitCount > 0 {
+			utils.Warnf("连接池有等待连接 - 等待连接数: %d", stats.WaitCount)
+		}
+	}
 }
 
 // logQueryStats 记录查询统计
@@ -0,0 +1,123 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/redact"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	gormutils "gorm.io/gorm/utils"
+)
+
+// slowQueryThreshold 慢查询阈值
+const slowQueryThreshold = time.Second
+
+// sqlLog 数据库日志，InitDB 时创建
+var sqlLog = newSQLLogger(logger.Info)
+
+// sqlLogger 通过统一日志记录器输出的gorm日志
+// 日志附加请求上下文中的请求ID、用户ID和链路追踪ID
+// 平时按基础级别输出，可在运行时临时开启全部SQL语句的输出
+type sqlLogger struct {
+	level logger.LogLevel
+	until *atomic.Int64 // SQL日志的截止时间（Unix纳秒），0 表示未开启，LogMode 派生的日志共享
+}
+
+// newSQLLogger 创建按 level 输出的数据库日志
+func newSQLLogger(level logger.LogLevel) *sqlLogger {
+	return &sqlLogger{
+		level: level,
+		until: new(atomic.Int64),
+	}
+}
+
+// LogMode 创建指定级别的数据库日志
+func (l *sqlLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &sqlLogger{level: level, until: l.until}
+}
+
+// Info 输出信息日志
+func (l *sqlLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.effectiveLevel() >= logger.Info {
+		utils.FromContext(ctx).Infof(msg, data...)
+	}
+}
+
+// Warn 输出警告日志
+func (l *sqlLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.effectiveLevel() >= logger.Warn {
+		utils.FromContext(ctx).Warnf(msg, data...)
+	}
+}
+
+// Error 输出错误日志
+func (l *sqlLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.effectiveLevel() >= logger.Error {
+		utils.FromContext(ctx).Errorf(msg, data...)
+	}
+}
+
+// ParamsFilter 对SQL参数脱敏，敏感列的值和参数中的邮箱、令牌等不会出现在日志中
+func (l *sqlLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, redact.Default().SQLParams(sql, params)
+}
+
+// Trace 输出SQL日志，执行出错时输出错误，超过慢查询阈值时输出警告
+func (l *sqlLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	level := l.effectiveLevel()
+	if level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	// source 须在 Trace 中直接获取调用位置，跳过gorm内部的调用栈
+	fields := func(source string) []interface{} {
+		sql, rows := fc()
+		return []interface{}{"sql", redact.String(sql), "rows", rows, "latency", elapsed, "source", source}
+	}
+
+	switch {
+	case err != nil && level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		utils.FromContext(ctx).Error("sql error", append(fields(gormutils.FileWithLineNum()), "error", redact.String(err.Error()))...)
+	case elapsed > slowQueryThreshold && level >= logger.Warn:
+		utils.FromContext(ctx).Warn(fmt.Sprintf("slow sql >= %v", slowQueryThreshold), fields(gormutils.FileWithLineNum())...)
+	case level >= logger.Info:
+		utils.FromContext(ctx).Info("sql", fields(gormutils.FileWithLineNum())...)
+	}
+}
+
+// effectiveLevel 当前生效的级别，SQL日志开启期间输出全部SQL语句
+func (l *sqlLogger) effectiveLevel() logger.LogLevel {
+	if l.enabled() {
+		return logger.Info
+	}
+	return l.level
+}
+
+// enabled SQL日志是否处于开启期间
+func (l *sqlLogger) enabled() bool {
+	until := l.until.Load()
+	return until != 0 && time.Now().UnixNano() < until
+}
+
+// EnableSQLLog 开启SQL日志，duration 后自动恢复为基础级别，duration 不大于0时立即关闭
+func EnableSQLLog(duration time.Duration) {
+	if duration <= 0 {
+		sqlLog.until.Store(0)
+		return
+	}
+	sqlLog.until.Store(time.Now().Add(duration).UnixNano())
+}
+
+// SQLLogUntil 获取SQL日志的截止时间，未开启时返回零值
+func SQLLogUntil() time.Time {
+	if !sqlLog.enabled() {
+		return time.Time{}
+	}
+	return time.Unix(0, sqlLog.until.Load())
+}
@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/tracing"
+	"gitee.com/NextEraAbyss/gin-template/models"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"github.com/google/uuid"
+)
+
+// Event 领域事件.
+type Event struct {
+	ID            string          `json:"id"`             // 事件唯一ID，消费方应据此去重
+	Type          string          `json:"type"`           // 事件类型
+	AggregateType string          `json:"aggregate_type"` // 聚合类型
+	AggregateID   string          `json:"aggregate_id"`   // 聚合ID
+	Payload       json.RawMessage `json:"payload"`        // 事件内容
+	RequestID     string          `json:"request_id"`     // 产生事件的请求ID
+	TraceParent   string          `json:"-"`              // 产生事件的请求的 traceparent，投递时作为父span
+	OccurredAt    time.Time       `json:"occurred_at"`    // 发生时间
+}
+
+// NewEvent 创建待写入发件箱的事件，请求ID和链路信息从上下文中获取.
+func NewEvent(ctx context.Context, eventType, aggregateType string, aggregateID interface{}, payload interface{}) (*models.OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化事件内容失败: %w", err)
+	}
+
+	now := time.Now()
+
+	return &models.OutboxEvent{
+		EventID:       uuid.New().String(),
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   fmt.Sprint(aggregateID),
+		Payload:       string(data),
+		RequestID:     utils.RequestIDFromContext(ctx),
+		TraceParent:   tracing.TraceParent(ctx),
+		Status:        models.OutboxStatusPending,
+		NextAttemptAt: now,
+	}, nil
+}
+
+// FromModel 将发件箱记录转换为事件.
+func FromModel(m *models.OutboxEvent) Event {
+	payload := json.RawMessage(m.Payload)
+	if len(payload) == 0 {
+		payload = json.RawMessage("null")
+	}
+
+	return Event{
+		ID:            m.EventID,
+		Type:          m.EventType,
+		AggregateType: m.AggregateType,
+		AggregateID:   m.AggregateID,
+		Payload:       payload,
+		RequestID:     m.RequestID,
+		TraceParent:   m.TraceParent,
+		OccurredAt:    m.CreatedAt,
+	}
+}
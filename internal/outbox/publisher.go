@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Publisher 事件发布器.
+// Publish 返回错误时事件会被重试，因此实现方可能收到重复事件.
+type Publisher interface {
+	// Name 发布器名称.
+	Name() string
+	// Publish 发布事件.
+	Publish(ctx context.Context, event Event) error
+}
+
+// Handler 进程内事件处理函数.
+type Handler func(ctx context.Context, event Event) error
+
+// AllEvents 订阅所有事件类型.
+const AllEvents = "*"
+
+// Bus 进程内事件总线.
+// 作为发布器注册到投递器后，事件提交成功才会分发给订阅者.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewBus 创建进程内事件总线.
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[string][]Handler),
+	}
+}
+
+// Subscribe 订阅事件，eventType 为 AllEvents 时订阅所有事件.
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Name 发布器名称.
+func (b *Bus) Name() string {
+	return "bus"
+}
+
+// Publish 同步调用所有订阅者，任一订阅者失败时返回错误.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers[event.Type])+len(b.handlers[AllEvents]))
+	handlers = append(handlers, b.handlers[event.Type]...)
+	handlers = append(handlers, b.handlers[AllEvents]...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := safeHandle(ctx, handler, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// safeHandle 调用订阅者并捕获panic.
+func safeHandle(ctx context.Context, handler Handler, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("事件处理panic: %v", r)
+		}
+	}()
+
+	return handler(ctx, event)
+}
@@ -0,0 +1,56 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/tracing"
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultStreamMaxLen Redis Stream 默认保留的最大消息数（近似值）.
+const defaultStreamMaxLen = 100000
+
+// RedisStreamPublisher 将事件写入 Redis Stream.
+type RedisStreamPublisher struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamPublisher 创建 Redis Stream 发布器，maxLen 小于等于0时使用默认值.
+func NewRedisStreamPublisher(client *redis.Client, stream string, maxLen int64) *RedisStreamPublisher {
+	if maxLen <= 0 {
+		maxLen = defaultStreamMaxLen
+	}
+
+	return &RedisStreamPublisher{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+// Name 发布器名称.
+func (p *RedisStreamPublisher) Name() string {
+	return "redis_stream"
+}
+
+// Publish 使用 XADD 写入事件.
+func (p *RedisStreamPublisher) Publish(ctx context.Context, event Event) error {
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.stream,
+		MaxLen: p.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"id":             event.ID,
+			"type":           event.Type,
+			"aggregate_type": event.AggregateType,
+			"aggregate_id":   event.AggregateID,
+			"payload":        string(event.Payload),
+			"request_id":     event.RequestID,
+			"traceparent":    tracing.TraceParent(ctx),
+			"occurred_at":    event.OccurredAt.Format(time.RFC3339Nano),
+		},
+	}).Err()
+}
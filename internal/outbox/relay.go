@@ -0,0 +1,183 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/tracing"
+	"gitee.com/NextEraAbyss/gin-template/models"
+	"gitee.com/NextEraAbyss/gin-template/repositories"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// maxLastErrorLength 记录的错误信息最大字符数，与 outbox_events.last_error 列宽一致.
+	maxLastErrorLength = 1000
+	// purgeInterval 清理已投递事件的间隔.
+	purgeInterval = time.Hour
+)
+
+// RelayConfig 投递器配置.
+type RelayConfig struct {
+	PollInterval time.Duration // 轮询间隔
+	BatchSize    int           // 每次领取的事件数
+	Lease        time.Duration // 领取后的租约时长，超时未完成的事件会被重新领取
+	MaxAttempts  int           // 最大投递次数，超过后进入死信
+	BaseBackoff  time.Duration // 首次重试等待时间，之后按2倍递增
+	MaxBackoff   time.Duration // 最大重试等待时间
+	Retention    time.Duration // 已投递事件保留时长，小于等于0表示永久保留
+}
+
+// DefaultRelayConfig 默认投递器配置.
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		Lease:        time.Minute,
+		MaxAttempts:  10,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   10 * time.Minute,
+		Retention:    7 * 24 * time.Hour,
+	}
+}
+
+// Relay 发件箱投递器.
+// 轮询发件箱并将事件投递给所有发布器，全部成功才标记为已投递，
+// 任一发布器失败则按指数退避重试，因此投递语义为至少一次.
+type Relay struct {
+	repo       repositories.OutboxRepository
+	publishers []Publisher
+	config     RelayConfig
+}
+
+// NewRelay 创建投递器.
+func NewRelay(repo repositories.OutboxRepository, config RelayConfig, publishers ...Publisher) *Relay {
+	return &Relay{
+		repo:       repo,
+		publishers: publishers,
+		config:     config,
+	}
+}
+
+// Run 运行投递循环，直到上下文取消.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	lastPurge := time.Now()
+
+	for {
+		// 领取满一批时说明可能还有积压，立即继续处理
+		for r.dispatchBatch(ctx) >= r.config.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		if r.config.Retention > 0 && time.Since(lastPurge) >= purgeInterval {
+			r.purge(ctx)
+			lastPurge = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchBatch 领取并投递一批事件，返回领取的事件数.
+func (r *Relay) dispatchBatch(ctx context.Context) int {
+	events, err := r.repo.Claim(ctx, r.config.BatchSize, r.config.Lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			utils.Errorf("领取发件箱事件失败: %v", err)
+		}
+		return 0
+	}
+
+	for _, event := range events {
+		r.dispatch(ctx, event)
+	}
+
+	return len(events)
+}
+
+// dispatch 投递单个事件并记录结果.
+// 投递在产生事件的请求的链路下进行，出站请求携带原请求的请求ID.
+func (r *Relay) dispatch(ctx context.Context, m *models.OutboxEvent) {
+	event := FromModel(m)
+
+	ctx = utils.WithRequestID(tracing.ContextWithTraceParent(ctx, event.TraceParent), event.RequestID)
+	ctx, span := tracing.Tracer().Start(ctx, "outbox.dispatch "+event.Type,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("outbox.event_id", event.ID),
+			attribute.Int("outbox.attempt", m.Attempts+1),
+		),
+	)
+	defer span.End()
+
+	var failures []string
+	for _, publisher := range r.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", publisher.Name(), err))
+		}
+	}
+
+	if len(failures) == 0 {
+		if err := r.repo.MarkDelivered(ctx, m.ID); err != nil {
+			utils.Errorf("标记事件 %s 已投递失败: %v", m.EventID, err)
+		}
+		return
+	}
+
+	lastError := strings.Join(failures, "; ")
+	span.SetStatus(codes.Error, lastError)
+	lastError = utils.TruncateString(lastError, maxLastErrorLength)
+
+	attempts := m.Attempts + 1
+	dead := attempts >= r.config.MaxAttempts
+	if dead {
+		utils.Errorf("事件 %s(%s) 投递失败 %d 次，进入死信: %s", m.EventID, m.EventType, attempts, lastError)
+	} else {
+		utils.Warnf("事件 %s(%s) 第 %d 次投递失败: %s", m.EventID, m.EventType, attempts, lastError)
+	}
+
+	if err := r.repo.MarkFailed(ctx, m.ID, time.Now().Add(r.backoff(attempts)), lastError, dead); err != nil {
+		utils.Errorf("记录事件 %s 投递失败状态失败: %v", m.EventID, err)
+	}
+}
+
+// backoff 计算第 attempts 次失败后的重试等待时间.
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.config.BaseBackoff
+	for i := 1; i < attempts && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > r.config.MaxBackoff {
+		delay = r.config.MaxBackoff
+	}
+
+	return delay
+}
+
+// purge 清理超过保留期的已投递事件.
+func (r *Relay) purge(ctx context.Context) {
+	deleted, err := r.repo.DeleteDeliveredBefore(ctx, time.Now().Add(-r.config.Retention))
+	if err != nil {
+		utils.Errorf("清理已投递事件失败: %v", err)
+		return
+	}
+
+	if deleted > 0 {
+		utils.Infof("已清理已投递事件 %d 条", deleted)
+	}
+}
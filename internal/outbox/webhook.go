@@ -0,0 +1,81 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/tracing"
+)
+
+const (
+	// webhookTimeout 单次Webhook请求超时时间.
+	webhookTimeout = 10 * time.Second
+	// WebhookSignatureHeader Webhook签名头，值为 sha256=HMAC-SHA256(secret, body) 的十六进制编码.
+	WebhookSignatureHeader = "X-Signature"
+)
+
+// WebhookPublisher 通过HTTP POST将事件推送到外部地址.
+type WebhookPublisher struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookPublisher 创建Webhook发布器，secret 为空时不签名.
+func NewWebhookPublisher(url, secret string) *WebhookPublisher {
+	return &WebhookPublisher{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: webhookTimeout, Transport: tracing.Transport(nil)},
+	}
+}
+
+// Name 发布器名称.
+func (p *WebhookPublisher) Name() string {
+	return "webhook:" + p.url
+}
+
+// Publish 发送事件，非2xx响应视为失败.
+func (p *WebhookPublisher) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", event.Type)
+	if len(p.secret) > 0 {
+		mac := hmac.New(sha256.New, p.secret)
+		mac.Write(body)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// 读完响应体以便复用连接
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook返回状态码 %d", resp.StatusCode)
+	}
+
+	return nil
+}
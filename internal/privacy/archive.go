@@ -0,0 +1,78 @@
+package privacy
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// 导出文件格式
+const (
+	FormatZIP  = "zip"
+	FormatJSON = "json"
+)
+
+// Manifest 导出文件的说明信息.
+type Manifest struct {
+	RequestID   uint      `json:"request_id"`   // 隐私请求ID
+	UserID      uint      `json:"user_id"`      // 用户ID
+	GeneratedAt time.Time `json:"generated_at"` // 生成时间
+	Modules     []string  `json:"modules"`      // 包含的模块
+}
+
+// Section 单个模块的导出数据.
+type Section struct {
+	Module string
+	Data   interface{}
+}
+
+// WriteArchive 将导出数据写入 w.
+// zip 格式包含 manifest.json 和每个模块一个JSON文件，json 格式为单个JSON文档.
+func WriteArchive(w io.Writer, format string, manifest Manifest, sections []Section) error {
+	manifest.Modules = make([]string, 0, len(sections))
+	for _, s := range sections {
+		manifest.Modules = append(manifest.Modules, s.Module)
+	}
+
+	switch format {
+	case FormatJSON:
+		data := make(map[string]interface{}, len(sections))
+		for _, s := range sections {
+			data[s.Module] = s.Data
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Manifest Manifest               `json:"manifest"`
+			Data     map[string]interface{} `json:"data"`
+		}{manifest, data})
+	case FormatZIP:
+		zw := zip.NewWriter(w)
+		if err := writeZipJSON(zw, "manifest.json", manifest); err != nil {
+			return err
+		}
+		for _, s := range sections {
+			if err := writeZipJSON(zw, s.Module+".json", s.Data); err != nil {
+				return err
+			}
+		}
+		return zw.Close()
+	default:
+		return fmt.Errorf("不支持的导出格式: %s", format)
+	}
+}
+
+// writeZipJSON 向压缩包写入一个JSON文件.
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
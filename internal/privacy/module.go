@@ -0,0 +1,86 @@
+package privacy
+
+import (
+	"context"
+	"fmt"
+)
+
+// Module 业务模块的隐私数据处理器.
+// 模块可以实现 Exporter、Eraser 中的一个或两个.
+type Module interface {
+	// Name 模块名称，用作导出文件名和合规记录.
+	Name() string
+}
+
+// Exporter 导出用户在本模块中的数据.
+type Exporter interface {
+	Module
+	// Export 返回可被JSON序列化的用户数据.
+	Export(ctx context.Context, userID uint) (interface{}, error)
+}
+
+// Eraser 删除或匿名化用户在本模块中的数据.
+// 实现必须是幂等的：处理失败后会整体重试.
+type Eraser interface {
+	Module
+	// Erase 删除或匿名化用户数据.
+	Erase(ctx context.Context, userID uint) error
+}
+
+// Registry 隐私数据处理器注册表.
+// 导出和删除均按注册顺序执行.
+type Registry struct {
+	modules []Module
+	byName  map[string]Module
+}
+
+// NewRegistry 创建隐私数据处理器注册表.
+func NewRegistry() *Registry {
+	return &Registry{
+		byName: make(map[string]Module),
+	}
+}
+
+// Register 注册处理器，名称重复时返回错误.
+func (r *Registry) Register(modules ...Module) error {
+	for _, m := range modules {
+		if _, exists := r.byName[m.Name()]; exists {
+			return fmt.Errorf("隐私数据处理器 %s 已注册", m.Name())
+		}
+		r.byName[m.Name()] = m
+		r.modules = append(r.modules, m)
+	}
+
+	return nil
+}
+
+// MustRegister 注册处理器，名称重复时panic.
+func (r *Registry) MustRegister(modules ...Module) {
+	if err := r.Register(modules...); err != nil {
+		panic(err)
+	}
+}
+
+// Exporters 返回所有实现了 Exporter 的处理器.
+func (r *Registry) Exporters() []Exporter {
+	var exporters []Exporter
+	for _, m := range r.modules {
+		if e, ok := m.(Exporter); ok {
+			exporters = append(exporters, e)
+		}
+	}
+
+	return exporters
+}
+
+// Erasers 返回所有实现了 Eraser 的处理器.
+func (r *Registry) Erasers() []Eraser {
+	var erasers []Eraser
+	for _, m := range r.modules {
+		if e, ok := m.(Eraser); ok {
+			erasers = append(erasers, e)
+		}
+	}
+
+	return erasers
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/utils"
+)
+
+// defaultFallbackCooldown 主限流器出错后使用降级限流器的时长.
+const defaultFallbackCooldown = 10 * time.Second
+
+// FallbackLimiter 带降级的限流器.
+// 主限流器（通常为Redis）出错后，在冷却时间内直接使用降级限流器，避免每个请求都等待超时.
+type FallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+	cooldown time.Duration
+	until    atomic.Int64 // 降级截止时间（UnixNano）
+}
+
+// NewFallbackLimiter 创建带降级的限流器，cooldown 小于等于0时使用默认值.
+func NewFallbackLimiter(primary, fallback Limiter, cooldown time.Duration) *FallbackLimiter {
+	if cooldown <= 0 {
+		cooldown = defaultFallbackCooldown
+	}
+
+	return &FallbackLimiter{
+		primary:  primary,
+		fallback: fallback,
+		cooldown: cooldown,
+	}
+}
+
+// Allow 检查是否允许请求.
+func (l *FallbackLimiter) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	if time.Now().UnixNano() < l.until.Load() {
+		return l.fallback.Allow(ctx, policy, key)
+	}
+
+	result, err := l.primary.Allow(ctx, policy, key)
+	if err == nil {
+		return result, nil
+	}
+
+	// 只有切换到降级状态的请求记录日志
+	until := time.Now().Add(l.cooldown).UnixNano()
+	if previous := l.until.Swap(until); previous < time.Now().UnixNano() {
+		utils.Warnf("限流器不可用，%v 内使用进程内限流: %v", l.cooldown, err)
+	}
+
+	return l.fallback.Allow(ctx, policy, key)
+}
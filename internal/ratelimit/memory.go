@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryLimiter 进程内限流器，使用与 RedisLimiter 相同的GCRA算法.
+// 计数只在当前实例有效，多实例部署时主要作为Redis不可用时的降级方案.
+type MemoryLimiter struct {
+	mu   sync.Mutex
+	tats map[string]time.Time // 各键的理论到达时间
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMemoryLimiter 创建进程内限流器，并启动后台协程定期清理已恢复额度的键.
+// 不再使用时应调用 Close 停止.
+func NewMemoryLimiter() *MemoryLimiter {
+	l := &MemoryLimiter{
+		tats: make(map[string]time.Time),
+		stop: make(chan struct{}),
+	}
+
+	go l.cleanup(time.Minute)
+	return l
+}
+
+// Allow 检查是否允许请求.
+func (l *MemoryLimiter) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	interval := policy.emissionInterval()
+	burstOffset := interval * time.Duration(policy.burst())
+	storage := storageKey(policy, key)
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tat, exists := l.tats[storage]
+	if !exists || tat.Before(now) {
+		tat = now
+	}
+
+	newTAT := tat.Add(interval)
+	diff := now.Sub(newTAT.Add(-burstOffset))
+	if diff < 0 {
+		return Result{
+			Allowed:    false,
+			Limit:      policy.Limit,
+			RetryAfter: -diff,
+			ResetAfter: tat.Sub(now),
+		}, nil
+	}
+
+	l.tats[storage] = newTAT
+	return Result{
+		Allowed:    true,
+		Limit:      policy.Limit,
+		Remaining:  int(diff / interval),
+		ResetAfter: newTAT.Sub(now),
+	}, nil
+}
+
+// Close 停止后台清理协程，可重复调用.
+func (l *MemoryLimiter) Close() error {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+
+	return nil
+}
+
+// cleanup 定期删除额度已完全恢复的键，直到调用 Close.
+func (l *MemoryLimiter) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			l.mu.Lock()
+			for key, tat := range l.tats {
+				if tat.Before(now) {
+					delete(l.tats, key)
+				}
+			}
+			l.mu.Unlock()
+		}
+	}
+}
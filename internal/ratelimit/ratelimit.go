@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keyPrefix 限流计数在存储中的键前缀.
+const keyPrefix = "ratelimit:"
+
+// Policy 限流策略.
+// 使用GCRA算法：每个周期允许 Limit 个请求，请求均匀恢复，最多允许 Burst 个请求集中到达.
+type Policy struct {
+	Name   string        // 策略名称，不同策略的计数相互独立
+	Limit  int           // 每个周期允许的请求数
+	Period time.Duration // 周期
+	Burst  int           // 突发容量，0表示与 Limit 相同
+}
+
+// burst 获取突发容量.
+func (p Policy) burst() int {
+	if p.Burst > 0 {
+		return p.Burst
+	}
+
+	return p.Limit
+}
+
+// emissionInterval 两个请求之间的平均间隔.
+func (p Policy) emissionInterval() time.Duration {
+	return p.Period / time.Duration(p.Limit)
+}
+
+// String 按 IETF RateLimit-Policy 格式输出策略，如 "100;w=60".
+func (p Policy) String() string {
+	return fmt.Sprintf("%d;w=%d", p.Limit, int(p.Period.Seconds()))
+}
+
+// ParsePolicy 解析 "次数/周期" 格式的策略，周期为Go时长格式或单位 s、m、h，如 "100/1m"、"10/s".
+func ParsePolicy(name, spec string) (Policy, error) {
+	count, period, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("限流策略格式错误: %s", spec)
+	}
+
+	limit, err := strconv.Atoi(count)
+	if err != nil || limit <= 0 {
+		return Policy{}, fmt.Errorf("限流策略次数错误: %s", spec)
+	}
+
+	switch period {
+	case "s", "m", "h":
+		period = "1" + period
+	}
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return Policy{}, fmt.Errorf("限流策略周期错误: %s", spec)
+	}
+
+	return Policy{Name: name, Limit: limit, Period: duration}, nil
+}
+
+// Result 限流检查结果.
+type Result struct {
+	Allowed    bool          // 是否允许请求
+	Limit      int           // 策略允许的请求数
+	Remaining  int           // 剩余可用请求数
+	RetryAfter time.Duration // 被拒绝时，距离下次允许请求的时间
+	ResetAfter time.Duration // 距离额度完全恢复的时间
+}
+
+// Limiter 限流器.
+type Limiter interface {
+	// Allow 检查 key 在策略下是否允许一次请求，并消耗一次额度.
+	Allow(ctx context.Context, policy Policy, key string) (Result, error)
+}
+
+// storageKey 生成限流计数的存储键.
+func storageKey(policy Policy, key string) string {
+	return keyPrefix + policy.Name + ":" + key
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// gcraScript GCRA限流脚本，使用Redis服务器时间，多实例共享同一份计数.
+// 键中保存理论到达时间（TAT，毫秒），过期时间为额度完全恢复所需的时间.
+var gcraScript = redis.NewScript(`
+local key = KEYS[1]
+local emission_interval = tonumber(ARGV[1])
+local burst_offset = tonumber(ARGV[2])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + tonumber(time[2]) / 1000
+
+local tat = tonumber(redis.call("GET", key))
+if not tat or tat < now then
+	tat = now
+end
+
+local new_tat = tat + emission_interval
+local diff = now - (new_tat - burst_offset)
+if diff < 0 then
+	return {0, 0, tostring(-diff), tostring(tat - now)}
+end
+
+local reset_after = new_tat - now
+redis.call("SET", key, tostring(new_tat), "PX", math.ceil(reset_after))
+return {1, math.floor(diff / emission_interval), "0", tostring(reset_after)}
+`)
+
+// RedisLimiter 基于Redis的分布式限流器.
+type RedisLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisLimiter 创建Redis限流器.
+func NewRedisLimiter(client *redis.Client) *RedisLimiter {
+	return &RedisLimiter{client: client}
+}
+
+// Allow 检查是否允许请求.
+func (l *RedisLimiter) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	interval := float64(policy.emissionInterval()) / float64(time.Millisecond)
+	burstOffset := interval * float64(policy.burst())
+
+	values, err := gcraScript.Run(ctx, l.client, []string{storageKey(policy, key)}, interval, burstOffset).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+
+	return Result{
+		Allowed:    allowed == 1,
+		Limit:      policy.Limit,
+		Remaining:  int(remaining),
+		RetryAfter: parseMillis(values[2]),
+		ResetAfter: parseMillis(values[3]),
+	}, nil
+}
+
+// parseMillis 解析脚本返回的毫秒数.
+func parseMillis(value interface{}) time.Duration {
+	s, _ := value.(string)
+	ms, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(math.Ceil(ms)) * time.Millisecond
+}
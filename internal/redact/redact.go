@@ -0,0 +1,238 @@
+package redact
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"gitee.com/NextEraAbyss/gin-template/config"
+)
+
+// DefaultMask 默认的脱敏占位符.
+const DefaultMask = "[REDACTED]"
+
+// Config 脱敏配置.
+type Config struct {
+	Fields      []string // 敏感字段名（JSON键、表单字段、数据库列），名称或其后缀匹配即脱敏，不区分大小写，忽略 _ 和 -
+	Headers     []string // 敏感请求头，不区分大小写
+	QueryParams []string // 敏感查询参数，匹配规则同 Fields
+	Patterns    []string // 额外的敏感内容正则，匹配到的内容整体替换
+	Mask        string   // 脱敏占位符，默认为 [REDACTED]
+}
+
+// DefaultConfig 默认脱敏配置.
+func DefaultConfig() Config {
+	return Config{
+		Fields: []string{
+			"password", "passwd", "pwd", "secret", "token", "apikey", "accesskey",
+			"authorization", "cookie", "salt", "email", "phone", "mobile", "idcard", "creditcard",
+		},
+		Headers: []string{
+			"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Auth-Token",
+		},
+		QueryParams: []string{
+			"password", "secret", "token", "apikey", "accesskey", "signature", "sig", "code", "email", "phone",
+		},
+		Mask: DefaultMask,
+	}
+}
+
+// builtinDetectors 内置的敏感内容检测规则.
+var builtinDetectors = []string{
+	// 邮箱
+	`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`,
+	// Bearer令牌
+	`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`,
+	// JWT
+	`eyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`,
+	// bcrypt密码哈希
+	`\$2[abxy]?\$\d{2}\$[./A-Za-z0-9]{53}`,
+}
+
+// keyValueSecret 匹配文本中 password=xxx、"token": "xxx" 形式的密钥，只替换值.
+var keyValueSecret = regexp.MustCompile(`(?i)("?\b(?:password|passwd|secret|token|api[_-]?key|access[_-]?key)"?\s*[=:]\s*)("[^"]*"|'[^']*'|[^\s,;&"']+)`)
+
+// Redactor 敏感数据脱敏器.
+type Redactor struct {
+	fields      []string
+	headers     map[string]bool
+	queryParams []string
+	detectors   []*regexp.Regexp
+	mask        string
+}
+
+// defaultRedactor 默认脱敏器.
+var defaultRedactor atomic.Pointer[Redactor]
+
+func init() {
+	r, err := New(DefaultConfig())
+	if err != nil {
+		panic(err)
+	}
+	defaultRedactor.Store(r)
+}
+
+// New 创建脱敏器.
+func New(config Config) (*Redactor, error) {
+	r := &Redactor{
+		headers: make(map[string]bool, len(config.Headers)),
+		mask:    config.Mask,
+	}
+	if r.mask == "" {
+		r.mask = DefaultMask
+	}
+
+	for _, field := range config.Fields {
+		r.fields = append(r.fields, normalize(field))
+	}
+	for _, param := range config.QueryParams {
+		r.queryParams = append(r.queryParams, normalize(param))
+	}
+	for _, header := range config.Headers {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	for _, pattern := range append(append([]string(nil), builtinDetectors...), config.Patterns...) {
+		detector, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("脱敏正则表达式无效: %s: %w", pattern, err)
+		}
+		r.detectors = append(r.detectors, detector)
+	}
+
+	return r, nil
+}
+
+// NewFromConfig 根据应用配置创建脱敏器，在内置名单的基础上追加配置的名单.
+func NewFromConfig(cfg *config.Config) (*Redactor, error) {
+	redactConfig := DefaultConfig()
+	redactConfig.Fields = append(redactConfig.Fields, cfg.Redact.Fields...)
+	redactConfig.Headers = append(redactConfig.Headers, cfg.Redact.Headers...)
+	redactConfig.QueryParams = append(redactConfig.QueryParams, cfg.Redact.QueryParams...)
+	redactConfig.Patterns = append(redactConfig.Patterns, cfg.Redact.Patterns...)
+	return New(redactConfig)
+}
+
+// SetDefault 设置默认脱敏器.
+func SetDefault(r *Redactor) {
+	defaultRedactor.Store(r)
+}
+
+// Default 获取默认脱敏器.
+func Default() *Redactor {
+	return defaultRedactor.Load()
+}
+
+// String 使用默认脱敏器替换文本中的敏感内容.
+func String(s string) string {
+	return Default().String(s)
+}
+
+// String 替换文本中的邮箱、令牌、密码哈希等敏感内容.
+func (r *Redactor) String(s string) string {
+	if s == "" {
+		return s
+	}
+
+	s = keyValueSecret.ReplaceAllString(s, "${1}"+r.mask)
+	for _, detector := range r.detectors {
+		s = detector.ReplaceAllLiteralString(s, r.mask)
+	}
+	return s
+}
+
+// IsSensitiveField 判断字段是否敏感.
+func (r *Redactor) IsSensitiveField(name string) bool {
+	return matchName(r.fields, name)
+}
+
+// IsSensitiveHeader 判断请求头是否敏感.
+func (r *Redactor) IsSensitiveHeader(name string) bool {
+	return r.headers[http.CanonicalHeaderKey(name)]
+}
+
+// Query 对查询字符串脱敏，敏感参数的值替换为占位符，其余参数的值按内容检测.
+func (r *Redactor) Query(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+
+	parts := strings.Split(rawQuery, "&")
+	for i, part := range parts {
+		key, value, found := strings.Cut(part, "=")
+		if !found || value == "" {
+			continue
+		}
+
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if matchName(r.queryParams, name) {
+			parts[i] = key + "=" + r.mask
+			continue
+		}
+
+		decoded, err := url.QueryUnescape(value)
+		if err != nil {
+			decoded = value
+		}
+		if redacted := r.String(decoded); redacted != decoded {
+			parts[i] = key + "=" + redacted
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// Headers 对请求头脱敏，返回便于记录日志的副本.
+func (r *Redactor) Headers(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+	for name, values := range header {
+		if r.IsSensitiveHeader(name) {
+			result[name] = r.mask
+			continue
+		}
+		result[name] = r.String(strings.Join(values, ", "))
+	}
+	return result
+}
+
+// Value 对字段值脱敏，敏感字段返回占位符，字符串按内容检测.
+func (r *Redactor) Value(field string, value interface{}) interface{} {
+	if field != "" && r.IsSensitiveField(field) {
+		return r.mask
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.String(v)
+	case []byte:
+		return r.String(string(v))
+	case *string:
+		if v != nil {
+			return r.String(*v)
+		}
+	}
+	return value
+}
+
+// normalize 统一字段名，忽略大小写、下划线和中划线.
+func normalize(name string) string {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, "_", "")
+	return strings.ReplaceAll(name, "-", "")
+}
+
+// matchName 字段名等于或以任一名称结尾时匹配，如 old_password 匹配 password.
+func matchName(names []string, name string) bool {
+	name = normalize(name)
+	for _, candidate := range names {
+		if strings.HasSuffix(name, candidate) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// sqlLookback 为占位符查找列名时向前查看的最大长度.
+const sqlLookback = 256
+
+var (
+	// insertColumns 匹配 INSERT 语句的列名列表.
+	insertColumns = regexp.MustCompile(`(?is)^\s*(?:INSERT|REPLACE)\s+(?:IGNORE\s+)?INTO\s+\S+\s*\(([^)]*)\)\s*VALUES`)
+	// insertValuesEnd 匹配 INSERT 语句 VALUES 之后的子句.
+	insertValuesEnd = regexp.MustCompile(`(?i)\bON\s+(?:DUPLICATE|CONFLICT)\b|\bRETURNING\b`)
+	// comparedColumn 匹配占位符前的比较或赋值表达式中的列名，如 email = ?、name IN (?, ?.
+	comparedColumn = regexp.MustCompile(`(?is)[\x60"]?([A-Za-z_][A-Za-z0-9_]*)[\x60"]?\s*(?:=|<>|!=|<=|>=|<|>|\bLIKE|\bIN\s*\((?:\s*\?\s*,)*)\s*$`)
+)
+
+// SQLParams 对SQL参数脱敏.
+// 根据占位符对应的列名判断敏感字段，INSERT 语句按列名列表的位置对应，
+// 其余语句取占位符前比较或赋值表达式中的列名；字符串参数同时按内容检测.
+func (r *Redactor) SQLParams(sql string, params []interface{}) []interface{} {
+	if len(params) == 0 {
+		return params
+	}
+
+	columns := placeholderColumns(sql, len(params))
+	redacted := make([]interface{}, len(params))
+	for i, param := range params {
+		redacted[i] = r.Value(columns[i], param)
+	}
+	return redacted
+}
+
+// placeholderColumns 获取每个占位符对应的列名，无法确定时为空.
+func placeholderColumns(sql string, count int) []string {
+	columns := make([]string, count)
+
+	var insert []string
+	valuesStart, valuesEnd := -1, len(sql)
+	if match := insertColumns.FindStringSubmatchIndex(sql); match != nil {
+		for _, column := range strings.Split(sql[match[2]:match[3]], ",") {
+			insert = append(insert, strings.Trim(strings.TrimSpace(column), "`\""))
+		}
+		valuesStart = match[1]
+		if end := insertValuesEnd.FindStringIndex(sql[valuesStart:]); end != nil {
+			valuesEnd = valuesStart + end[0]
+		}
+	}
+
+	index := 0
+	inValues := 0
+	var quote byte
+	for i := 0; i < len(sql) && index < count; i++ {
+		ch := sql[i]
+		if quote != 0 {
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+		case '?':
+			// INSERT 的 VALUES 中按位置对应列名，多行插入时循环对应
+			if valuesStart >= 0 && i >= valuesStart && i < valuesEnd && len(insert) > 0 {
+				columns[index] = insert[inValues%len(insert)]
+				inValues++
+			} else {
+				start := max(0, i-sqlLookback)
+				if match := comparedColumn.FindStringSubmatch(sql[start:i]); match != nil {
+					columns[index] = match[1]
+				}
+			}
+			index++
+		}
+	}
+
+	return columns
+}
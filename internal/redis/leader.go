@@ -0,0 +1,86 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// leaderKeyPrefix 选主锁的键前缀
+const leaderKeyPrefix = "leader:"
+
+// ElectionConfig 选主配置
+type ElectionConfig struct {
+	TTL           time.Duration // 主节点锁有效期，主节点异常退出后其他实例最迟在该时间后接替
+	RetryInterval time.Duration // 非主节点竞选的间隔
+}
+
+// DefaultElectionConfig 返回默认选主配置
+func DefaultElectionConfig() ElectionConfig {
+	return ElectionConfig{
+		TTL:           15 * time.Second,
+		RetryInterval: 5 * time.Second,
+	}
+}
+
+// LeaderElection 基于分布式锁的选主
+// 多个实例使用相同名称竞选，同一时刻只有获得锁的实例执行回调
+type LeaderElection struct {
+	client *redis.Client
+	name   string
+	config ElectionConfig
+	leader atomic.Bool
+}
+
+// NewLeaderElection 创建选主实例
+func NewLeaderElection(client *redis.Client, name string, config ElectionConfig) *LeaderElection {
+	defaults := DefaultElectionConfig()
+	if config.TTL <= 0 {
+		config.TTL = defaults.TTL
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaults.RetryInterval
+	}
+
+	return &LeaderElection{
+		client: client,
+		name:   name,
+		config: config,
+	}
+}
+
+// IsLeader 当前实例是否为主节点
+func (e *LeaderElection) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run 持续竞选，成为主节点后执行 fn，直到 ctx 取消
+// 失去主节点身份时传给 fn 的上下文会被取消，fn 返回后释放主节点身份并重新竞选
+func (e *LeaderElection) Run(ctx context.Context, fn func(ctx context.Context)) {
+	lockConfig := LockConfig{TTL: e.config.TTL, AutoExtend: true}
+
+	for {
+		err := WithLock(ctx, e.client, leaderKeyPrefix+e.name, lockConfig, func(ctx context.Context) error {
+			e.leader.Store(true)
+			defer e.leader.Store(false)
+
+			utils.Infof("当选主节点: %s", e.name)
+			fn(ctx)
+			utils.Infof("卸任主节点: %s", e.name)
+			return nil
+		})
+		if err != nil && !errors.Is(err, ErrLockNotObtained) && ctx.Err() == nil {
+			utils.Warnf("竞选主节点失败: %s, 错误: %v", e.name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.config.RetryInterval):
+		}
+	}
+}
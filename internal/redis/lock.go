@@ -0,0 +1,262 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrLockNotObtained 锁已被其他持有者占用
+	ErrLockNotObtained = errors.New("未能获取锁")
+	// ErrLockNotHeld 锁已过期或已被其他持有者获取
+	ErrLockNotHeld = errors.New("未持有锁")
+)
+
+// lockKeyPrefix 锁在Redis中的键前缀
+const lockKeyPrefix = "lock:"
+
+// releaseScript 仅当令牌匹配时删除锁，避免误删其他持有者的锁
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// extendScript 仅当令牌匹配时延长锁的有效期
+var extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// LockConfig 锁配置
+type LockConfig struct {
+	TTL           time.Duration // 锁有效期，持有者异常退出后锁在该时间后自动释放
+	RetryInterval time.Duration // 获取失败时的重试间隔，0表示不重试
+	AutoExtend    bool          // 持有期间是否自动续期，每隔 TTL/3 续期一次
+}
+
+// DefaultLockConfig 返回默认锁配置
+func DefaultLockConfig() LockConfig {
+	return LockConfig{
+		TTL:           30 * time.Second,
+		RetryInterval: 100 * time.Millisecond,
+		AutoExtend:    true,
+	}
+}
+
+// Lock 基于Redis的分布式锁
+// 每次获取生成随机令牌，续期和释放时校验令牌，只有持有者能操作自己的锁
+type Lock struct {
+	client *redis.Client
+	key    string
+	token  string
+	ttl    time.Duration
+
+	lost     chan struct{} // 锁丢失（续期失败或已释放）时关闭
+	lostOnce sync.Once
+	stop     chan struct{} // 停止自动续期
+	stopOnce sync.Once
+	done     chan struct{} // 自动续期协程退出时关闭
+}
+
+// Obtain 获取分布式锁
+// 配置了 RetryInterval 时会持续重试直到获取成功或 ctx 取消，否则锁被占用时立即返回 ErrLockNotObtained
+func Obtain(ctx context.Context, client *redis.Client, key string, config LockConfig) (*Lock, error) {
+	if config.TTL <= 0 {
+		config.TTL = DefaultLockConfig().TTL
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lock{
+		client: client,
+		key:    lockKeyPrefix + key,
+		token:  token,
+		ttl:    config.TTL,
+		lost:   make(chan struct{}),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	var obtainedAt time.Time
+	for {
+		// 有效期从发送命令时开始计算，不包含等待响应的时间
+		obtainedAt = time.Now()
+		ok, err := client.SetNX(ctx, lock.key, token, config.TTL).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			break
+		}
+
+		if config.RetryInterval <= 0 {
+			return nil, ErrLockNotObtained
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(config.RetryInterval):
+		}
+	}
+
+	if config.AutoExtend {
+		go lock.keepAlive(obtainedAt)
+	} else {
+		close(lock.done)
+	}
+
+	return lock, nil
+}
+
+// Key 获取锁在Redis中的键
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Token 获取锁的令牌
+func (l *Lock) Token() string {
+	return l.token
+}
+
+// Lost 返回锁丢失时关闭的通道，持有者应在收到通知后停止受保护的操作
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Extend 延长锁的有效期，锁已不属于当前持有者时返回 ErrLockNotHeld
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	result, err := extendScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if result == 0 {
+		l.markLost()
+		return ErrLockNotHeld
+	}
+
+	return nil
+}
+
+// Release 释放锁并停止自动续期，锁已不属于当前持有者时返回 ErrLockNotHeld
+func (l *Lock) Release(ctx context.Context) error {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+	<-l.done
+	defer l.markLost()
+
+	result, err := releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return err
+	}
+	if result == 0 {
+		return ErrLockNotHeld
+	}
+
+	return nil
+}
+
+// keepAlive 定期续期，直到释放锁或续期失败
+// Redis暂时不可用时继续重试；下次续期无法在有效期结束前完成时即认为锁已丢失，
+// 保证持有者在Redis中的锁过期之前停止受保护的操作
+func (l *Lock) keepAlive(lastExtended time.Time) {
+	defer close(l.done)
+
+	interval := l.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			// 续期须在当前有效期结束前完成，有效期从发送续期命令时开始计算
+			sentAt := time.Now()
+			ctx, cancel := context.WithDeadline(context.Background(), minTime(sentAt.Add(interval), lastExtended.Add(l.ttl)))
+			err := l.Extend(ctx, l.ttl)
+			cancel()
+
+			switch {
+			case err == nil:
+				lastExtended = sentAt
+			case errors.Is(err, ErrLockNotHeld):
+				utils.Warnf("分布式锁已丢失: %s", l.key)
+				return
+			default:
+				utils.Warnf("分布式锁续期失败: %s, 错误: %v", l.key, err)
+				if time.Since(lastExtended)+interval >= l.ttl {
+					utils.Warnf("分布式锁无法在有效期内续期，视为已丢失: %s", l.key)
+					l.markLost()
+					return
+				}
+			}
+		}
+	}
+}
+
+// minTime 返回较早的时间
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// markLost 标记锁已丢失
+func (l *Lock) markLost() {
+	l.lostOnce.Do(func() {
+		close(l.lost)
+	})
+}
+
+// WithLock 获取锁后执行 fn，执行完毕后释放锁
+// 传给 fn 的上下文在锁丢失或 ctx 取消时取消
+func WithLock(ctx context.Context, client *redis.Client, key string, config LockConfig, fn func(ctx context.Context) error) error {
+	lock, err := Obtain(ctx, client, key, config)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Release(context.WithoutCancel(ctx)); err != nil && !errors.Is(err, ErrLockNotHeld) {
+			utils.Warnf("释放分布式锁失败: %s, 错误: %v", lock.Key(), err)
+		}
+	}()
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-lock.Lost():
+			cancel()
+		case <-lockCtx.Done():
+		}
+	}()
+
+	return fn(lockCtx)
+}
+
+// newLockToken 生成随机令牌
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
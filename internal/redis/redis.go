@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"gitee.com/NextEraAbyss/gin-template/config"
+	"gitee.com/NextEraAbyss/gin-template/internal/tracing"
+	"gitee.com/NextEraAbyss/gin-template/utils"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -17,6 +19,7 @@ var (
 )
 
 // InitRedis 初始化Redis连接
+// Redis暂不可用时不会退出，客户端会在使用时自动重连，可用状态由健康检查反映
 func InitRedis(config *config.Config) *redis.Client {
 	// 创建Redis客户端
 	client := redis.NewClient(&redis.Options{
@@ -31,13 +34,16 @@ func InitRedis(config *config.Config) *redis.Client {
 		WriteTimeout: 3 * time.Second, // 写入超时时间
 	})
 
+	// 为请求中的Redis命令创建子span
+	client.AddHook(tracing.NewRedisHook())
+
 	// 测试连接
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	_, err := client.Ping(ctx).Result()
 	if err != nil {
-		panic(fmt.Sprintf("Failed to connect to Redis: %v", err))
+		utils.Errorf("Redis连接失败，将在使用时重试: %v", err)
 	}
 
 	Client = client
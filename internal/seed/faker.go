@@ -0,0 +1,61 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+var (
+	firstNames = []string{
+		"james", "mary", "john", "linda", "robert", "lisa", "michael", "emma",
+		"david", "olivia", "daniel", "sophia", "wei", "fang", "lei", "jing",
+	}
+	lastNames = []string{
+		"smith", "johnson", "brown", "taylor", "miller", "wilson", "moore", "clark",
+		"wang", "li", "zhang", "liu", "chen", "yang", "zhao", "huang",
+	}
+	emailDomains = []string{"example.com", "example.org", "example.net"}
+)
+
+// Faker 简单的假数据生成器.
+// 使用固定种子时生成的数据可复现.
+type Faker struct {
+	rnd *rand.Rand
+}
+
+// NewFaker 创建假数据生成器.
+func NewFaker(seed int64) *Faker {
+	return &Faker{
+		rnd: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// FirstName 随机名.
+func (f *Faker) FirstName() string {
+	return firstNames[f.rnd.Intn(len(firstNames))]
+}
+
+// LastName 随机姓.
+func (f *Faker) LastName() string {
+	return lastNames[f.rnd.Intn(len(lastNames))]
+}
+
+// Nickname 随机昵称，格式为 "First Last".
+func (f *Faker) Nickname() string {
+	return capitalize(f.FirstName()) + " " + capitalize(f.LastName())
+}
+
+// Email 根据用户名生成邮箱.
+func (f *Faker) Email(username string) string {
+	return fmt.Sprintf("%s@%s", username, emailDomains[f.rnd.Intn(len(emailDomains))])
+}
+
+// capitalize 首字母大写.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
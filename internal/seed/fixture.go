@@ -0,0 +1,171 @@
+package seed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoadFixtures 从YAML或JSON文件加载测试数据.
+// 每个文件对应一张表，文件名（不含扩展名）即表名，内容为记录列表；
+// 传入目录时按文件名顺序加载目录下所有 .yml/.yaml/.json 文件.
+// 字段值按数据库原始值写入（例如密码需填写哈希值），主键冲突时以文件内容覆盖，
+// 表含有 created_at/updated_at 列而记录未提供时自动填充当前时间.
+// 所有文件在同一个事务中加载.
+func LoadFixtures(ctx context.Context, db *gorm.DB, paths ...string) error {
+	files, err := expandFixturePaths(paths)
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, file := range files {
+			if err := loadFixtureFile(tx, file); err != nil {
+				return fmt.Errorf("加载数据文件 %s 失败: %w", file, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// expandFixturePaths 展开目录，返回所有数据文件路径.
+func expandFixturePaths(paths []string) ([]string, error) {
+	var files []string
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		dirFiles := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if !entry.IsDir() && isFixtureFile(entry.Name()) {
+				dirFiles = append(dirFiles, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(dirFiles)
+		files = append(files, dirFiles...)
+	}
+
+	return files, nil
+}
+
+// isFixtureFile 判断是否为支持的数据文件.
+func isFixtureFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yml", ".yaml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+// loadFixtureFile 加载单个数据文件.
+func loadFixtureFile(db *gorm.DB, file string) error {
+	rows, err := readFixtureFile(file)
+	if err != nil {
+		return err
+	}
+
+	table := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	if !db.Migrator().HasTable(table) {
+		return fmt.Errorf("表 %s 不存在", table)
+	}
+
+	now := time.Now()
+	for _, column := range []string{"created_at", "updated_at"} {
+		if !db.Migrator().HasColumn(table, column) {
+			continue
+		}
+		for _, row := range rows {
+			if _, exists := row[column]; !exists {
+				row[column] = now
+			}
+		}
+	}
+
+	for _, row := range rows {
+		columns := make([]string, 0, len(row))
+		for column, value := range row {
+			columns = append(columns, column)
+			row[column] = normalizeFixtureValue(value)
+		}
+		sort.Strings(columns)
+
+		err := db.Table(table).
+			Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns(columns)}).
+			Create(row).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readFixtureFile 读取并解析数据文件.
+func readFixtureFile(file string) ([]map[string]interface{}, error) {
+	// #nosec G304 -- 数据文件路径由开发者通过命令行指定
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]interface{}
+	if strings.ToLower(filepath.Ext(file)) == ".json" {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err = decoder.Decode(&rows)
+	} else {
+		err = yaml.Unmarshal(data, &rows)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// normalizeFixtureValue 将解析结果转换为数据库驱动可接受的值.
+func normalizeFixtureValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}, []interface{}:
+		// 嵌套结构按JSON写入，适用于 json 类型的列
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		return string(data)
+	default:
+		return v
+	}
+}
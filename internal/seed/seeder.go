@@ -0,0 +1,144 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gitee.com/NextEraAbyss/gin-template/config"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"gorm.io/gorm"
+)
+
+// Seeder 数据填充器.
+// 实现必须是幂等的：重复执行不会产生重复数据.
+type Seeder interface {
+	// Name 填充器名称，用于命令行选择.
+	Name() string
+	// Environments 允许运行的环境，为空表示所有环境.
+	Environments() []string
+	// Run 执行数据填充，db 已处于事务中.
+	Run(ctx context.Context, db *gorm.DB) error
+}
+
+// Registry 填充器注册表.
+type Registry struct {
+	seeders []Seeder
+	byName  map[string]Seeder
+}
+
+// NewRegistry 创建填充器注册表.
+func NewRegistry() *Registry {
+	return &Registry{
+		byName: make(map[string]Seeder),
+	}
+}
+
+// DefaultRegistry 创建包含内置填充器的注册表.
+func DefaultRegistry(cfg *config.Config) *Registry {
+	registry := NewRegistry()
+	registry.MustRegister(
+		NewAdminSeeder(cfg.Seed.AdminUsername, cfg.Seed.AdminEmail, cfg.Seed.AdminPassword),
+		NewDemoUserSeeder(cfg.Seed.DemoUsers),
+	)
+
+	return registry
+}
+
+// Register 注册填充器，名称重复时返回错误.
+func (r *Registry) Register(seeders ...Seeder) error {
+	for _, s := range seeders {
+		if _, exists := r.byName[s.Name()]; exists {
+			return fmt.Errorf("填充器 %s 已注册", s.Name())
+		}
+		r.byName[s.Name()] = s
+		r.seeders = append(r.seeders, s)
+	}
+
+	return nil
+}
+
+// MustRegister 注册填充器，名称重复时panic.
+func (r *Registry) MustRegister(seeders ...Seeder) {
+	if err := r.Register(seeders...); err != nil {
+		panic(err)
+	}
+}
+
+// Names 返回所有已注册的填充器名称.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.seeders))
+	for _, s := range r.seeders {
+		names = append(names, s.Name())
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Run 在指定环境下执行填充器.
+// names 为空时执行该环境允许的所有填充器（按注册顺序），
+// 否则按给定顺序执行，名称不存在或不允许在该环境运行时返回错误.
+func (r *Registry) Run(ctx context.Context, db *gorm.DB, env string, names []string) error {
+	seeders, err := r.selectSeeders(env, names)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range seeders {
+		utils.Infof("执行数据填充: %s", s.Name())
+
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return s.Run(ctx, tx)
+		})
+		if err != nil {
+			return fmt.Errorf("数据填充 %s 失败: %w", s.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// selectSeeders 根据环境和名称筛选填充器.
+func (r *Registry) selectSeeders(env string, names []string) ([]Seeder, error) {
+	if len(names) == 0 {
+		seeders := make([]Seeder, 0, len(r.seeders))
+		for _, s := range r.seeders {
+			if allowedIn(s, env) {
+				seeders = append(seeders, s)
+			}
+		}
+
+		return seeders, nil
+	}
+
+	seeders := make([]Seeder, 0, len(names))
+	for _, name := range names {
+		s, exists := r.byName[name]
+		if !exists {
+			return nil, fmt.Errorf("未知的填充器: %s", name)
+		}
+		if !allowedIn(s, env) {
+			return nil, fmt.Errorf("填充器 %s 不允许在 %s 环境运行", name, env)
+		}
+		seeders = append(seeders, s)
+	}
+
+	return seeders, nil
+}
+
+// allowedIn 判断填充器是否允许在指定环境运行.
+func allowedIn(s Seeder, env string) bool {
+	envs := s.Environments()
+	if len(envs) == 0 {
+		return true
+	}
+
+	for _, e := range envs {
+		if e == env {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,142 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/config"
+	"gitee.com/NextEraAbyss/gin-template/models"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	// demoUserPassword 演示用户的统一密码.
+	demoUserPassword = "Demo@123456"
+	// demoFakerSeed 演示数据的随机种子，保证多次执行生成相同的数据.
+	demoFakerSeed = 20240101
+)
+
+// AdminSeeder 管理员用户填充器.
+type AdminSeeder struct {
+	username string
+	email    string
+	password string
+}
+
+// NewAdminSeeder 创建管理员用户填充器.
+func NewAdminSeeder(username, email, password string) *AdminSeeder {
+	return &AdminSeeder{
+		username: username,
+		email:    email,
+		password: password,
+	}
+}
+
+// Name 填充器名称.
+func (s *AdminSeeder) Name() string {
+	return "admin"
+}
+
+// Environments 允许在所有环境运行.
+func (s *AdminSeeder) Environments() []string {
+	return nil
+}
+
+// Run 创建管理员用户，管理员已存在时跳过，不修改密码；同名的非管理员用户已存在时返回错误.
+func (s *AdminSeeder) Run(ctx context.Context, db *gorm.DB) error {
+	if s.password == "" {
+		return errors.New("未设置管理员密码，请配置 SEED_ADMIN_PASSWORD")
+	}
+	if err := utils.ValidatePasswordStrength(s.password); err != nil {
+		return fmt.Errorf("管理员密码强度不足: %w", err)
+	}
+
+	// 同名用户已存在时不授予管理员权限，避免将普通用户注册的同名账号提升为管理员
+	var existing models.User
+	err := db.Where("username = ?", s.username).Take(&existing).Error
+	switch {
+	case err == nil && existing.IsAdmin:
+		utils.Debugf("管理员 %s 已存在，跳过", s.username)
+		return nil
+	case err == nil:
+		return fmt.Errorf("用户名 %s 已被非管理员用户使用，请更换 SEED_ADMIN_USERNAME 或手动处理该用户", s.username)
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+
+	return createUserIfNotExists(db, &models.User{
+		Username: s.username,
+		Password: s.password,
+		Email:    s.email,
+		Nickname: "Administrator",
+		IsAdmin:  true,
+	})
+}
+
+// DemoUserSeeder 演示用户填充器.
+type DemoUserSeeder struct {
+	count int
+}
+
+// NewDemoUserSeeder 创建演示用户填充器.
+func NewDemoUserSeeder(count int) *DemoUserSeeder {
+	return &DemoUserSeeder{count: count}
+}
+
+// Name 填充器名称.
+func (s *DemoUserSeeder) Name() string {
+	return "demo_users"
+}
+
+// Environments 仅允许在开发和测试环境运行.
+func (s *DemoUserSeeder) Environments() []string {
+	return []string{config.EnvDevelopment, config.EnvTest}
+}
+
+// Run 创建演示用户，用户名为 demo_001 ~ demo_N，已存在的用户跳过.
+func (s *DemoUserSeeder) Run(ctx context.Context, db *gorm.DB) error {
+	faker := NewFaker(demoFakerSeed)
+
+	for i := 1; i <= s.count; i++ {
+		username := fmt.Sprintf("demo_%03d", i)
+		user := &models.User{
+			Username: username,
+			Password: demoUserPassword,
+			Email:    faker.Email(username),
+			Nickname: faker.Nickname(),
+		}
+
+		if err := createUserIfNotExists(db, user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createUserIfNotExists 用户名不存在时创建用户，Password 传入明文.
+func createUserIfNotExists(db *gorm.DB, user *models.User) error {
+	var count int64
+	if err := db.Model(&models.User{}).Where("username = ?", user.Username).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		utils.Debugf("用户 %s 已存在，跳过", user.Username)
+		return nil
+	}
+
+	hashedPassword, err := utils.HashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	user.Status = 1
+	user.LastLoginAt = time.Now()
+
+	return db.Create(user).Error
+}
@@ -0,0 +1,160 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName 本服务创建span使用的Tracer名称.
+const tracerName = "gitee.com/NextEraAbyss/gin-template"
+
+// 导出方式.
+const (
+	ExporterNone   = "none"   // 不导出，只在请求间传递 traceparent
+	ExporterOTLP   = "otlp"   // 通过OTLP导出到Collector
+	ExporterStdout = "stdout" // 输出到标准输出，用于本地调试
+	ExporterFile   = "file"   // 以JSON格式写入文件，用于本地调试
+)
+
+// Config 链路追踪配置.
+type Config struct {
+	ServiceName  string  // 服务名称
+	Environment  string  // 部署环境
+	Exporter     string  // 导出方式：none、otlp、stdout、file
+	OTLPEndpoint string  // OTLP接收地址，如 localhost:4317，为空时使用 OTEL_EXPORTER_OTLP_ENDPOINT
+	OTLPProtocol string  // OTLP协议：grpc 或 http
+	OTLPInsecure bool    // 是否使用不加密的连接
+	FilePath     string  // file 导出方式写入的文件路径
+	SampleRatio  float64 // 根span的采样比例，上游已决定采样时沿用上游的决定
+}
+
+// Init 初始化全局 TracerProvider 和 W3C Trace Context 传播器，返回关闭函数.
+// 导出方式为 none 时不创建span，但仍会透传请求中的 traceparent.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, closeFn, err := newExporter(ctx, cfg)
+	if err != nil || exporter == nil {
+		return func(context.Context) error { return nil }, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.DeploymentEnvironmentName(cfg.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("创建链路追踪资源失败: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeFn != nil {
+			if closeErr := closeFn(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+// newExporter 根据配置创建导出器，导出方式为 none 时返回 nil.
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, func() error, error) {
+	switch strings.ToLower(cfg.Exporter) {
+	case "", ExporterNone:
+		return nil, nil, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		return exporter, nil, err
+	case ExporterFile:
+		if err := os.MkdirAll(filepath.Dir(cfg.FilePath), 0o755); err != nil {
+			return nil, nil, fmt.Errorf("创建链路追踪文件目录失败: %w", err)
+		}
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("打开链路追踪文件失败: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, nil, err
+		}
+		return exporter, file.Close, nil
+	case ExporterOTLP:
+		exporter, err := newOTLPExporter(ctx, cfg)
+		return exporter, nil, err
+	default:
+		return nil, nil, fmt.Errorf("不支持的链路追踪导出方式: %s", cfg.Exporter)
+	}
+}
+
+// newOTLPExporter 创建OTLP导出器，未配置的选项使用 OTEL_EXPORTER_OTLP_* 环境变量.
+func newOTLPExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	if strings.EqualFold(cfg.OTLPProtocol, "http") {
+		var opts []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.OTLPEndpoint))
+		}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+
+	var opts []otlptracegrpc.Option
+	if cfg.OTLPEndpoint != "" {
+		opts = append(opts, otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint))
+	}
+	if cfg.OTLPInsecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+// Tracer 返回本服务使用的Tracer.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// TraceParent 返回上下文中span的 traceparent 头，用于在异步处理中延续链路.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// ContextWithTraceParent 将 traceparent 解析为远程父span写入上下文.
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+}
+
+// hasParent 判断上下文中是否有父span，没有时数据库和Redis调用不单独创建span，
+// 避免后台轮询任务产生大量孤立的链路.
+func hasParent(ctx context.Context) bool {
+	return trace.SpanContextFromContext(ctx).IsValid()
+}
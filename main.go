@@ -11,8 +11,14 @@ import (
 	"time"
 
 	"gitee.com/NextEraAbyss/gin-template/config"
+	"gitee.com/NextEraAbyss/gin-template/internal/admin"
+	"gitee.com/NextEraAbyss/gin-template/internal/errorreport"
+	"gitee.com/NextEraAbyss/gin-template/internal/health"
+	"gitee.com/NextEraAbyss/gin-template/internal/metrics"
 	"gitee.com/NextEraAbyss/gin-template/internal/mysql"
+	"gitee.com/NextEraAbyss/gin-template/internal/redact"
 	"gitee.com/NextEraAbyss/gin-template/internal/redis"
+	"gitee.com/NextEraAbyss/gin-template/internal/tracing"
 	"gitee.com/NextEraAbyss/gin-template/routes"
 	"gitee.com/NextEraAbyss/gin-template/utils"
 
@@ -20,10 +26,6 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-const (
-	EnvProduction = "production"
-)
-
 // @title           Gin API Template
 // @version         1.0
 // @description     This is a sample server for a Gin API template.
@@ -49,7 +51,58 @@ func main() {
 	cfg := config.LoadConfig()
 
 	// 初始化日志.
-	utils.InitLogger(utils.INFO, os.Stdout, os.Stderr, true)
+	logConfig, err := utils.NewLogConfig(cfg)
+	utils.InitLogger(logConfig)
+	defer utils.CloseLogger()
+	if err != nil {
+		utils.Errorf("部分日志输出初始化失败: %v", err)
+	}
+
+	// 初始化敏感数据脱敏，在内置名单的基础上追加配置的名单.
+	redactor, err := redact.NewFromConfig(cfg)
+	if err != nil {
+		utils.Fatalf("Failed to initialize redaction: %v", err)
+	}
+	redact.SetDefault(redactor)
+
+	// 初始化错误上报，panic 和服务端错误发送到配置的发送方式.
+	transports, err := errorreport.NewTransports(cfg.ErrorReport.Transports, cfg.ErrorReport.SentryDSN, utils.RotateConfig{
+		Filename:   cfg.ErrorReport.FilePath,
+		MaxSizeMB:  cfg.Log.FileMaxSizeMB,
+		Daily:      cfg.Log.FileDaily,
+		MaxBackups: cfg.Log.FileMaxBackups,
+		MaxAgeDays: cfg.Log.FileMaxAgeDays,
+		Compress:   cfg.Log.FileCompress,
+	})
+	if err != nil {
+		utils.Fatalf("Failed to initialize error reporting: %v", err)
+	}
+	errorReporter := errorreport.New(errorreport.Config{
+		Transports:  transports,
+		Environment: cfg.Env,
+		Release:     cfg.ErrorReport.Release,
+		SampleRate:  cfg.ErrorReport.SampleRate,
+		GroupLimit:  cfg.ErrorReport.GroupLimit,
+		GlobalLimit: cfg.ErrorReport.GlobalLimit,
+		Window:      time.Duration(cfg.ErrorReport.WindowSeconds) * time.Second,
+		QueueSize:   cfg.ErrorReport.QueueSize,
+	})
+	errorreport.SetDefault(errorReporter)
+
+	// 初始化链路追踪，需在创建数据库和Redis客户端之前完成.
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName:  cfg.Tracing.ServiceName,
+		Environment:  cfg.Env,
+		Exporter:     cfg.Tracing.Exporter,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		OTLPProtocol: cfg.Tracing.OTLPProtocol,
+		OTLPInsecure: cfg.Tracing.OTLPInsecure,
+		FilePath:     cfg.Tracing.FilePath,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		utils.Fatalf("Failed to initialize tracing: %v", err)
+	}
 
 	// 初始化数据库.
 	db := mysql.InitDB(cfg)
@@ -58,26 +111,70 @@ func main() {
 	redisClient := redis.InitRedis(cfg)
 
 	// 设置Gin模式.
-	if cfg.Env == EnvProduction {
+	if cfg.Env == config.EnvProduction {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	// 创建Gin引擎.
-	router := gin.Default()
+	// 访问日志和panic恢复由全局中间件通过统一的日志记录器输出，不使用gin的默认中间件.
+	router := gin.New()
 
 	// 设置路由.
-	routes.SetupRoutes(router, db, redisClient)
+	routes.SetupRoutes(router, cfg, db, redisClient)
 
 	// 创建HTTP服务器.
+	// 处理时间由路由的超时中间件控制，连接读写超时需大于最长的处理超时，保证超时响应能够写出.
+	connTimeout := time.Duration(cfg.Request.LongTimeoutSeconds)*time.Second + 10*time.Second
 	srv := &http.Server{
 		Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
 		Handler:           router,
 		ReadHeaderTimeout: 10 * time.Second,
-		ReadTimeout:       30 * time.Second,
-		WriteTimeout:      30 * time.Second,
+		ReadTimeout:       connTimeout,
+		WriteTimeout:      connTimeout,
 		IdleTimeout:       120 * time.Second,
 	}
 
+	// 配置了独立监听地址时，在内部端口上提供指标接口.
+	var metricsSrv *http.Server
+	if cfg.Metrics.Enabled && cfg.Metrics.Addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle(cfg.Metrics.Path, metrics.Handler())
+		metricsSrv = &http.Server{
+			Addr:              cfg.Metrics.Addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		go func() {
+			utils.Infof("Metrics server is running on %s", cfg.Metrics.Addr)
+
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				utils.Errorf("Failed to start metrics server: %v", err)
+			}
+		}()
+	}
+
+	// 启用时在本机或内网地址上提供管理接口，不经过公网端口.
+	var adminSrv *http.Server
+	if cfg.Admin.Enabled {
+		if err := admin.ValidateAddr(cfg.Admin.Addr, cfg.Server.Port); err != nil {
+			utils.Fatalf("Invalid admin server address: %v", err)
+		}
+		adminSrv = &http.Server{
+			Addr:              cfg.Admin.Addr,
+			Handler:           admin.Handler(router),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		go func() {
+			utils.Infof("Admin server is running on %s", cfg.Admin.Addr)
+
+			if err := adminSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				utils.Errorf("Failed to start admin server: %v", err)
+			}
+		}()
+	}
+
 	// 在goroutine中启动服务器.
 	go func() {
 		utils.Infof("Server is running on port %d", cfg.Server.Port)
@@ -93,13 +190,43 @@ func main() {
 	<-quit
 	utils.Infof("Shutting down server...")
 
-	// 设置关闭超时.
+	// 先让就绪探针失败，等待负载均衡摘除流量后再停止接收请求.
+	health.DefaultRegistry.SetShuttingDown()
+	time.Sleep(time.Duration(cfg.Health.ShutdownDelaySeconds) * time.Second)
+
+	// 优雅关闭服务器，等待处理中的请求完成，等待时间与最长的处理超时相同.
+	// 超时后强制关闭连接，继续关闭其他组件，保证剩余的span和错误事件能够发送.
+	srvCtx, srvCancel := context.WithTimeout(context.Background(), connTimeout)
+	defer srvCancel()
+	if err := srv.Shutdown(srvCtx); err != nil {
+		utils.Errorf("Server forced to shutdown: %v", err)
+		_ = srv.Close()
+	}
+
+	// 关闭其他组件，使用单独的超时，不受服务器关闭耗时的影响.
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// 优雅关闭服务器.
-	if err := srv.Shutdown(ctx); err != nil {
-		utils.Fatalf("Server forced to shutdown: %v", err)
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			utils.Errorf("Metrics server forced to shutdown: %v", err)
+		}
+	}
+
+	if adminSrv != nil {
+		if err := adminSrv.Shutdown(ctx); err != nil {
+			utils.Errorf("Admin server forced to shutdown: %v", err)
+		}
+	}
+
+	// 导出剩余的span.
+	if err := shutdownTracing(ctx); err != nil {
+		utils.Errorf("Failed to shutdown tracing: %v", err)
+	}
+
+	// 发送剩余的错误事件.
+	if err := errorReporter.Close(ctx); err != nil {
+		utils.Errorf("Failed to shutdown error reporting: %v", err)
 	}
 
 	utils.Infof("Server exiting")
@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"strings"
@@ -44,6 +45,36 @@ func AuthMiddleware() gin.HandlerFunc {
 		// 将解析的claims保存到上下文中
 		c.Set("userID", uint(userID))
 		c.Set("username", claims.Issuer) // 使用Issuer存储username
+		c.Request = c.Request.WithContext(utils.WithUserID(c.Request.Context(), uint(userID)))
+		c.Next()
+	}
+}
+
+// AdminChecker 判断用户是否为管理员
+type AdminChecker func(ctx context.Context, userID uint) (bool, error)
+
+// AdminRequired 要求当前用户为管理员的中间件，须在 AuthMiddleware 之后使用
+func AdminRequired(isAdmin AdminChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := utils.UserIDFromContext(c.Request.Context())
+		if !ok {
+			utils.ResponseError(c, utils.CodeUnauthorized, "")
+			c.Abort()
+			return
+		}
+
+		admin, err := isAdmin(c.Request.Context(), userID)
+		if err != nil {
+			utils.LogAndResponseError(c, utils.CodeInternalError, err)
+			c.Abort()
+			return
+		}
+		if !admin {
+			utils.ResponseError(c, utils.CodeForbidden, "需要管理员权限")
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -0,0 +1,376 @@
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressConfig 响应压缩配置
+type CompressConfig struct {
+	Encodings    []string // 支持的压缩算法，按服务端优先顺序排列，可选 zstd、br、gzip
+	MinSize      int      // 响应内容达到该字节数才压缩，过小的响应压缩后可能反而更大
+	ContentTypes []string // 允许压缩的内容类型，以 / 结尾时按前缀匹配，如 text/
+}
+
+// DefaultCompressConfig 返回默认响应压缩配置
+func DefaultCompressConfig() CompressConfig {
+	return CompressConfig{
+		Encodings: []string{"zstd", "br", "gzip"},
+		MinSize:   1024,
+		ContentTypes: []string{"text/", "application/json", "application/x-ndjson", "application/javascript",
+			"application/xml", "image/svg+xml"},
+	}
+}
+
+// compressor 压缩编码器
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressorFactories 各压缩算法的编码器创建函数
+var compressorFactories = map[string]func() compressor{
+	"gzip": func() compressor {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	},
+	"br": func() compressor {
+		// 较低的级别在压缩率和CPU开销之间取得平衡，适合动态响应
+		return brotli.NewWriterLevel(nil, 4)
+	},
+	"zstd": func() compressor {
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithWindowSize(1<<20),
+			zstd.WithLowerEncoderMem(true))
+		return w
+	},
+}
+
+// Compress 响应压缩中间件
+// 根据 Accept-Encoding 协商压缩算法，只压缩允许的内容类型且大小达到阈值的响应，
+// 已设置 Content-Encoding 的响应和范围请求的响应不会压缩。流式响应在 Flush 时立即输出压缩数据；
+// 压缩后的响应与原始内容不再逐字节相同，强ETag会改为弱ETag，If-None-Match 按弱比较仍然匹配
+func Compress(config CompressConfig) gin.HandlerFunc {
+	defaults := DefaultCompressConfig()
+	if len(config.Encodings) == 0 {
+		config.Encodings = defaults.Encodings
+	}
+	if config.MinSize <= 0 {
+		config.MinSize = defaults.MinSize
+	}
+	if len(config.ContentTypes) == 0 {
+		config.ContentTypes = defaults.ContentTypes
+	}
+
+	pools := make(map[string]*sync.Pool)
+	encodings := make([]string, 0, len(config.Encodings))
+	for _, name := range config.Encodings {
+		name = strings.ToLower(strings.TrimSpace(name))
+		factory, ok := compressorFactories[name]
+		if !ok {
+			utils.Warnf("不支持的压缩算法: %s", name)
+			continue
+		}
+		encodings = append(encodings, name)
+		pools[name] = &sync.Pool{New: func() any { return factory() }}
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), encodings)
+
+		original := c.Writer
+		writer := &compressWriter{
+			ResponseWriter: original,
+			config:         &config,
+			encoding:       encoding,
+			pool:           pools[encoding],
+		}
+		c.Writer = writer
+		defer func() {
+			// 处理过程中发生panic时丢弃未输出的内容，由恢复中间件返回错误响应
+			if r := recover(); r != nil {
+				writer.discard()
+				c.Writer = original
+				panic(r)
+			}
+		}()
+		c.Next()
+		writer.close()
+		c.Writer = original
+	}
+}
+
+// negotiateEncoding 按 Accept-Encoding 中的权重选择压缩算法，权重相同时按服务端优先顺序，
+// 没有可用的算法时返回空字符串
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		if name == "x-gzip" {
+			name = "gzip"
+		}
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		if name == "*" {
+			wildcard = quality
+		} else {
+			qualities[name] = quality
+		}
+	}
+
+	best, bestQuality := "", 0.0
+	for _, name := range supported {
+		quality, ok := qualities[name]
+		if !ok {
+			quality = wildcard
+		}
+		if quality > bestQuality {
+			best, bestQuality = name, quality
+		}
+	}
+
+	return best
+}
+
+// compressWriter 压缩响应内容的 ResponseWriter
+// 响应内容先写入缓冲区，达到压缩阈值、Flush 或处理结束时决定是否压缩
+type compressWriter struct {
+	gin.ResponseWriter
+	config      *CompressConfig
+	encoding    string
+	pool        *sync.Pool
+	buf         bytes.Buffer
+	encoder     compressor
+	decided     bool // 是否已决定是否压缩
+	wroteHeader bool // 处理函数是否要求立即写入响应头
+}
+
+// WriteHeaderNow 记录写入要求，响应头在决定是否压缩后写入
+func (w *compressWriter) WriteHeaderNow() {
+	w.wroteHeader = true
+	if !w.decided && !bodyAllowedForStatus(w.Status()) {
+		w.decide(false)
+	}
+}
+
+// Write 写入响应内容
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		if !w.compressible(data) {
+			w.decide(false)
+		} else {
+			w.buf.Write(data)
+			if w.buf.Len() < w.config.MinSize {
+				return len(data), nil
+			}
+			w.decide(true)
+			if err := w.writeBuffered(); err != nil {
+				return 0, err
+			}
+			return len(data), nil
+		}
+	}
+
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写入响应内容
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 立即输出已写入的内容，流式响应不等待达到压缩阈值
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(w.buf.Len() > 0 && w.compressible(w.buf.Bytes()))
+		if err := w.writeBuffered(); err != nil {
+			return
+		}
+	}
+
+	if w.encoder != nil {
+		if err := w.encoder.Flush(); err != nil {
+			return
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Written 是否已写入响应
+func (w *compressWriter) Written() bool {
+	return w.wroteHeader || w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// compressible 判断响应是否可以压缩，data 用于在未设置 Content-Type 时识别内容类型
+func (w *compressWriter) compressible(data []byte) bool {
+	if w.encoding == "" || !bodyAllowedForStatus(w.Status()) || w.Status() == http.StatusPartialContent {
+		return false
+	}
+
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+		header.Set("Content-Type", contentType)
+	}
+
+	return w.allowedContentType(contentType)
+}
+
+// allowedContentType 判断内容类型是否在允许压缩的列表中
+func (w *compressWriter) allowedContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range w.config.ContentTypes {
+		if strings.HasSuffix(allowed, "/") && strings.HasPrefix(mediaType, allowed) || mediaType == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decide 决定是否压缩并设置相应的响应头
+func (w *compressWriter) decide(compress bool) {
+	w.decided = true
+
+	header := w.Header()
+	if header.Get("Content-Encoding") == "" && w.allowedContentType(header.Get("Content-Type")) {
+		// 同一资源是否压缩取决于请求的 Accept-Encoding，需要告知缓存按该请求头区分
+		addVary(header, "Accept-Encoding")
+	}
+
+	if !compress {
+		return
+	}
+
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	header.Del("Accept-Ranges")
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+
+	w.encoder = w.pool.Get().(compressor)
+	w.encoder.Reset(w.ResponseWriter)
+}
+
+// writeBuffered 输出缓冲区中的内容
+func (w *compressWriter) writeBuffered() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+
+	return err
+}
+
+// close 处理结束后输出剩余内容并归还编码器
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.decide(w.buf.Len() >= w.config.MinSize && w.compressible(w.buf.Bytes()))
+	}
+
+	if err := w.writeBuffered(); err != nil {
+		utils.Warnf("写入响应失败, 错误: %v", err)
+	}
+	if w.wroteHeader {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+
+	if w.encoder != nil {
+		if err := w.encoder.Close(); err != nil {
+			utils.Warnf("压缩响应失败, 错误: %v", err)
+		}
+		w.release()
+	}
+}
+
+// discard 丢弃未输出的内容并归还编码器
+func (w *compressWriter) discard() {
+	w.buf.Reset()
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		w.release()
+	}
+}
+
+// release 归还编码器，不再持有响应的引用
+func (w *compressWriter) release() {
+	w.encoder.Reset(io.Discard)
+	w.pool.Put(w.encoder)
+	w.encoder = nil
+}
+
+// bodyAllowedForStatus 判断状态码是否允许包含响应内容
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+
+	return true
+}
+
+// addVary 向 Vary 响应头添加请求头名称，已存在时不重复添加
+func addVary(header http.Header, name string) {
+	for _, value := range header.Values("Vary") {
+		for _, existing := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(existing), name) || strings.TrimSpace(existing) == "*" {
+				return
+			}
+		}
+	}
+
+	header.Add("Vary", name)
+}
@@ -1,20 +1,155 @@
 package middlewares
 
 import (
+	"regexp"
+	"strings"
 	"time"
 
+	"gitee.com/NextEraAbyss/gin-template/utils"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-// CorsMiddleware 配置CORS
-func CorsMiddleware() gin.HandlerFunc {
-	return cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	})
+// CORSPolicy 跨域策略
+type CORSPolicy struct {
+	// AllowOrigins 允许的来源，支持三种写法：
+	// 精确匹配如 https://example.com；通配子域名如 https://*.example.com；
+	// 以 / 包裹的正则表达式如 /^https://[a-z]+\.example\.com$/。"*" 表示允许任意来源，此时不允许携带凭证
+	AllowOrigins     []string
+	AllowMethods     []string      // 允许的请求方法
+	AllowHeaders     []string      // 允许的请求头
+	ExposeHeaders    []string      // 允许前端读取的响应头
+	AllowCredentials bool          // 是否允许携带Cookie和Authorization等凭证
+	MaxAge           time.Duration // 预检请求结果的缓存时间
+}
+
+// CORSRule 按请求路径前缀选择跨域策略
+type CORSRule struct {
+	PathPrefix string
+	Policy     CORSPolicy
+}
+
+// DefaultCORSPolicy 返回默认跨域策略，不允许任何跨域来源
+func DefaultCORSPolicy() CORSPolicy {
+	return CORSPolicy{
+		AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", RequestIDHeaderName, IdempotencyKeyHeader},
+		ExposeHeaders: []string{"Content-Length", RequestIDHeaderName, "ETag", IdempotentReplayedHeader,
+			"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
+		MaxAge: 12 * time.Hour,
+	}
+}
+
+// CORS 按请求路径选择跨域策略的中间件
+// 使用路径前缀最长的规则，未匹配任何规则时使用 defaultPolicy。
+// 预检请求没有对应的路由，不会经过路由组中间件，因此需要注册为全局中间件
+func CORS(defaultPolicy CORSPolicy, rules ...CORSRule) gin.HandlerFunc {
+	fallback := NewCORS(defaultPolicy)
+
+	handlers := make([]gin.HandlerFunc, len(rules))
+	for i, rule := range rules {
+		handlers[i] = NewCORS(rule.Policy)
+	}
+
+	return func(c *gin.Context) {
+		handler := fallback
+		longest := -1
+		for i, rule := range rules {
+			if strings.HasPrefix(c.Request.URL.Path, rule.PathPrefix) && len(rule.PathPrefix) > longest {
+				handler = handlers[i]
+				longest = len(rule.PathPrefix)
+			}
+		}
+
+		handler(c)
+	}
+}
+
+// NewCORS 创建使用指定策略的跨域中间件，来源不被允许时返回403并记录日志
+func NewCORS(policy CORSPolicy) gin.HandlerFunc {
+	config := cors.Config{
+		AllowMethods:     policy.AllowMethods,
+		AllowHeaders:     policy.AllowHeaders,
+		ExposeHeaders:    policy.ExposeHeaders,
+		AllowCredentials: policy.AllowCredentials,
+		MaxAge:           policy.MaxAge,
+	}
+
+	matcher := newOriginMatcher(policy.AllowOrigins)
+	if matcher.any {
+		// 浏览器不接受同时允许任意来源和携带凭证的响应
+		if policy.AllowCredentials {
+			utils.Warnf("跨域策略允许任意来源，已禁用携带凭证")
+			config.AllowCredentials = false
+		}
+		config.AllowAllOrigins = true
+	} else {
+		config.AllowOriginWithContextFunc = func(c *gin.Context, origin string) bool {
+			if matcher.match(origin) {
+				return true
+			}
+
+			utils.Warnf("拒绝跨域请求, 来源: %s, 方法: %s, 路径: %s", origin, c.Request.Method, c.Request.URL.Path)
+			return false
+		}
+	}
+
+	return cors.New(config)
+}
+
+// originMatcher 跨域来源匹配器
+type originMatcher struct {
+	any       bool
+	exact     map[string]struct{}
+	wildcards [][2]string // 通配符前后两部分
+	patterns  []*regexp.Regexp
+}
+
+// newOriginMatcher 解析允许的来源列表，无效的正则表达式会被忽略并记录日志
+func newOriginMatcher(origins []string) *originMatcher {
+	m := &originMatcher{exact: make(map[string]struct{})}
+
+	for _, origin := range origins {
+		switch {
+		case origin == "*":
+			m.any = true
+		case len(origin) > 2 && strings.HasPrefix(origin, "/") && strings.HasSuffix(origin, "/"):
+			pattern, err := regexp.Compile(origin[1 : len(origin)-1])
+			if err != nil {
+				utils.Errorf("跨域来源正则表达式无效: %s, 错误: %v", origin, err)
+				continue
+			}
+			m.patterns = append(m.patterns, pattern)
+		case strings.Contains(origin, "*"):
+			prefix, suffix, _ := strings.Cut(origin, "*")
+			m.wildcards = append(m.wildcards, [2]string{prefix, suffix})
+		default:
+			m.exact[strings.ToLower(origin)] = struct{}{}
+		}
+	}
+
+	return m
+}
+
+// match 判断来源是否被允许
+func (m *originMatcher) match(origin string) bool {
+	if _, ok := m.exact[strings.ToLower(origin)]; ok {
+		return true
+	}
+
+	for _, w := range m.wildcards {
+		// 通配部分必须非空且不能跨越路径，避免 https://*.example.com 匹配 https://.example.com
+		if len(origin) > len(w[0])+len(w[1]) && strings.HasPrefix(origin, w[0]) && strings.HasSuffix(origin, w[1]) &&
+			!strings.Contains(origin[len(w[0]):len(origin)-len(w[1])], "/") {
+			return true
+		}
+	}
+
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
 }
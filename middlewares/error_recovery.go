@@ -1,30 +1,88 @@
 package middlewares
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"net/http"
 	"runtime/debug"
 
+	"gitee.com/NextEraAbyss/gin-template/internal/errorreport"
+	"gitee.com/NextEraAbyss/gin-template/internal/redact"
 	"gitee.com/NextEraAbyss/gin-template/utils"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Recovery 恢复中间件
 // 捕获 panic，记录错误堆栈，并返回统一的错误响应
+// panic 和服务端错误（5xx）连同请求上下文发送到错误上报
 func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				// 记录错误堆栈
-				log.Printf("Panic: %v\nStack: %s", err, debug.Stack())
+				// 记录错误堆栈，请求头和错误信息中的敏感信息已脱敏
+				redactor := redact.Default()
+				utils.FromContext(c.Request.Context()).Error("panic recovered",
+					"error", redactor.String(fmt.Sprint(err)),
+					"method", c.Request.Method,
+					"path", c.Request.URL.Path,
+					"query", redactor.Query(c.Request.URL.RawQuery),
+					"headers", redactor.Headers(c.Request.Header),
+					"stack", string(debug.Stack()),
+				)
 
 				// 返回错误响应
 				utils.ResponseError(c, utils.CodeInternalError, "系统内部错误")
 
+				// 上报 panic，须在延迟函数中调用以获取 panic 发生位置的调用栈
+				errorreport.Default().CapturePanic(err, reportRequest(c))
+
 				// 中止请求处理
 				c.Abort()
 			}
 		}()
 
 		c.Next()
+
+		// 上报服务端错误，优先使用处理器记录的原始错误
+		if status := c.Writer.Status(); status >= http.StatusInternalServerError {
+			reporter := errorreport.Default()
+			if !reporter.Enabled() {
+				return
+			}
+
+			err := utils.ResponseErrorFromContext(c)
+			if err == nil && len(c.Errors) > 0 {
+				err = c.Errors.Last().Err
+			}
+			if err == nil {
+				err = errors.New(http.StatusText(status))
+			}
+			reporter.CaptureError(err, reportRequest(c))
+		}
+	}
+}
+
+// reportRequest 获取错误上报的请求上下文，请求头和查询参数已脱敏
+func reportRequest(c *gin.Context) *errorreport.Request {
+	redactor := redact.Default()
+	ctx := c.Request.Context()
+
+	req := &errorreport.Request{
+		Method:    c.Request.Method,
+		Route:     c.FullPath(),
+		Path:      c.Request.URL.Path,
+		Query:     redactor.Query(c.Request.URL.RawQuery),
+		Headers:   redactor.Headers(c.Request.Header),
+		Status:    c.Writer.Status(),
+		ClientIP:  c.ClientIP(),
+		RequestID: utils.RequestIDFromContext(ctx),
+	}
+	if userID, ok := utils.UserIDFromContext(ctx); ok {
+		req.UserID = userID
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		req.TraceID = spanContext.TraceID().String()
 	}
+	return req
 }
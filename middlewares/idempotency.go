@@ -0,0 +1,261 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/idempotency"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader 幂等键请求头
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader 标记响应为重放结果的响应头
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength 幂等键的最大长度
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyConfig 幂等中间件配置
+type IdempotencyConfig struct {
+	TTL     time.Duration // 响应保存时间，在此期间使用相同键的重试会收到保存的响应
+	LockTTL time.Duration // 处理中记录的有效期，应大于请求处理的最长时间
+}
+
+// DefaultIdempotencyConfig 返回默认幂等中间件配置
+func DefaultIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		TTL:     24 * time.Hour,
+		LockTTL: time.Minute,
+	}
+}
+
+// volatileHeaders 重放时不使用保存值的响应头，这些头与具体的某次请求相关
+// 保存的响应内容未经压缩，Content-Encoding 由压缩中间件按重放请求重新协商
+var volatileHeaders = canonicalHeaderSet(RequestIDHeaderName, "Date", "Set-Cookie", "Retry-After",
+	"RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Content-Encoding")
+
+// Idempotency 幂等中间件
+// 对携带 Idempotency-Key 的 POST、PUT、PATCH、DELETE 请求，保存请求指纹和完整响应：
+// 使用相同键的重试直接重放保存的响应；相同键但请求内容不同时返回422；
+// 原请求仍在处理时返回409。5xx响应不会保存，客户端可以使用相同的键重试。
+// 幂等键按用户隔离，需在认证中间件之后使用
+func Idempotency(store idempotency.Store, config IdempotencyConfig) gin.HandlerFunc {
+	defaults := DefaultIdempotencyConfig()
+	if config.TTL <= 0 {
+		config.TTL = defaults.TTL
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = defaults.LockTTL
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isUnsafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			utils.ResponseError(c, utils.CodeInvalidParams, "Idempotency-Key 长度不能超过255个字符")
+			c.Abort()
+			return
+		}
+
+		fingerprint, cleanup, err := requestFingerprint(c)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				utils.ResponseError(c, utils.CodeRequestTooLarge, "")
+			} else {
+				utils.ResponseError(c, utils.CodeInvalidParams, "读取请求内容失败")
+			}
+			c.Abort()
+			return
+		}
+		defer cleanup()
+
+		ctx := c.Request.Context()
+		storeKey := idempotencyScope(c) + ":" + key
+		token := uuid.New().String()
+
+		existing, acquired, err := store.Acquire(ctx, storeKey, fingerprint, token, config.LockTTL)
+		if err != nil {
+			// 存储不可用时按普通请求处理，避免影响正常业务
+			utils.Warnf("登记幂等请求失败, 错误: %v", err)
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				utils.ResponseError(c, utils.CodeIdempotencyMismatch, "")
+			case !existing.Completed:
+				utils.ResponseError(c, utils.CodeRequestInProgress, "")
+			default:
+				replayResponse(c, existing)
+			}
+			c.Abort()
+			return
+		}
+
+		original := c.Writer
+		writer := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		defer func() {
+			// 处理过程中发生panic时释放记录，允许客户端重试
+			if r := recover(); r != nil {
+				c.Writer = original
+				releaseIdempotencyKey(ctx, store, storeKey, token)
+				panic(r)
+			}
+		}()
+		c.Next()
+		c.Writer = original
+
+		original.WriteHeader(writer.status)
+		original.WriteHeaderNow()
+		_, _ = original.Write(writer.body.Bytes())
+
+		if writer.status >= http.StatusInternalServerError {
+			releaseIdempotencyKey(ctx, store, storeKey, token)
+			return
+		}
+
+		record := &idempotency.Record{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      writer.status,
+			Header:      persistentHeaders(original.Header()),
+			Body:        writer.body.Bytes(),
+		}
+		if err := store.Complete(context.WithoutCancel(ctx), storeKey, token, record, config.TTL); err != nil {
+			utils.Warnf("保存幂等请求响应失败, 错误: %v", err)
+		}
+	}
+}
+
+// replayResponse 重放保存的响应
+func replayResponse(c *gin.Context, record *idempotency.Record) {
+	header := c.Writer.Header()
+	for name, values := range record.Header {
+		header[name] = values
+	}
+	header.Set(IdempotentReplayedHeader, "true")
+
+	c.Writer.WriteHeader(record.Status)
+	c.Writer.WriteHeaderNow()
+	_, _ = c.Writer.Write(record.Body)
+}
+
+// releaseIdempotencyKey 删除当前请求登记的幂等记录
+func releaseIdempotencyKey(ctx context.Context, store idempotency.Store, key, token string) {
+	if err := store.Release(context.WithoutCancel(ctx), key, token); err != nil {
+		utils.Warnf("释放幂等请求记录失败, 错误: %v", err)
+	}
+}
+
+// persistentHeaders 复制需要保存的响应头
+func persistentHeaders(header http.Header) map[string][]string {
+	result := make(map[string][]string, len(header))
+	for name, values := range header {
+		if _, volatile := volatileHeaders[http.CanonicalHeaderKey(name)]; volatile {
+			continue
+		}
+		result[name] = append([]string(nil), values...)
+	}
+
+	return result
+}
+
+// canonicalHeaderSet 创建按规范化名称索引的响应头集合
+func canonicalHeaderSet(names ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+
+	return set
+}
+
+// idempotencyScope 幂等键的作用范围，已登录时按用户隔离，否则按IP隔离
+func idempotencyScope(c *gin.Context) string {
+	if id, exists := c.Get("userID"); exists {
+		if userID, ok := id.(uint); ok {
+			return fmt.Sprintf("user:%d", userID)
+		}
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+// requestFingerprint 根据请求方法、路径、查询参数和请求内容计算指纹
+// 读取请求内容后替换为可重新读取的副本，返回的 cleanup 用于释放副本
+func requestFingerprint(c *gin.Context) (string, func(), error) {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "?" + c.Request.URL.Query().Encode() + "\n"))
+
+	cleanup, err := replayableBody(c.Request, h)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), cleanup, nil
+}
+
+// replayableBody 读取请求内容并写入 h，再将请求内容替换为可重新读取的副本
+// 文件上传请求的内容可能很大，边计算摘要边写入临时文件，不在内存中保存
+func replayableBody(req *http.Request, h hash.Hash) (func(), error) {
+	if !strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/") {
+		body, err := io.ReadAll(io.TeeReader(req.Body, h))
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return func() {}, nil
+	}
+
+	file, err := os.CreateTemp("", "idempotency-*")
+	if err != nil {
+		return nil, err
+	}
+	cleanup := func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+
+	if _, err := io.Copy(io.MultiWriter(file, h), req.Body); err != nil {
+		cleanup()
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, err
+	}
+	req.Body = io.NopCloser(file)
+	return cleanup, nil
+}
+
+// isUnsafeMethod 判断是否为会修改数据的请求方法
+func isUnsafeMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
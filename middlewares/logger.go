@@ -1,16 +1,16 @@
 package middlewares
 
 import (
-	"fmt"
 	"time"
 
+	"gitee.com/NextEraAbyss/gin-template/internal/redact"
+	"gitee.com/NextEraAbyss/gin-template/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 )
 
 // Logger 中间件
 // 记录请求的处理时间和响应状态
-// 输出格式: [请求方法] [状态码] [路径] [客户端IP] [响应时间] [错误信息]
+// 通过统一的日志记录器输出，自动附加请求ID、用户ID和链路追踪ID
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 请求开始时间
@@ -19,48 +19,48 @@ func Logger() gin.HandlerFunc {
 		// 处理请求
 		c.Next()
 
-		// 请求结束时间
-		endTime := time.Now()
-
 		// 计算请求处理时间
-		latency := endTime.Sub(startTime)
+		latency := time.Since(startTime)
 
-		// 请求方法和路径
-		method := c.Request.Method
+		// 请求方法和路径，查询参数中的敏感信息已脱敏
 		path := c.Request.URL.Path
 		if c.Request.URL.RawQuery != "" {
-			path = path + "?" + c.Request.URL.RawQuery
+			path = path + "?" + redact.Default().Query(c.Request.URL.RawQuery)
 		}
 
 		// 请求结果状态码
 		statusCode := c.Writer.Status()
 
-		// 客户端IP
-		clientIP := c.ClientIP()
-
-		// 构建日志条目
-		entry := logrus.WithFields(logrus.Fields{
-			"status":     statusCode,
-			"latency":    latency,
-			"client_ip":  clientIP,
-			"method":     method,
-			"path":       path,
-			"request_id": c.GetString("request_id"),
-		})
+		// 构建日志条目，用户ID由认证中间件写入请求上下文
+		logger := utils.FromContext(c.Request.Context()).With(
+			"status", statusCode,
+			"latency", latency,
+			"client_ip", c.ClientIP(),
+			"method", c.Request.Method,
+			"path", path,
+			"route", c.FullPath(),
+			"size", c.Writer.Size(),
+		)
 
 		// 判断请求是否出错
 		if len(c.Errors) > 0 {
 			// 记录错误信息
-			entry.Error(c.Errors.String())
-		} else {
-			// 根据状态码选择日志级别
-			if statusCode >= 500 {
-				entry.Error(fmt.Sprintf("[%s] %d %s %s %s", method, statusCode, path, clientIP, latency))
-			} else if statusCode >= 400 {
-				entry.Warn(fmt.Sprintf("[%s] %d %s %s %s", method, statusCode, path, clientIP, latency))
-			} else {
-				entry.Info(fmt.Sprintf("[%s] %d %s %s %s", method, statusCode, path, clientIP, latency))
+			errs := make([]string, 0, len(c.Errors))
+			for _, err := range c.Errors {
+				errs = append(errs, redact.String(err.Error()))
 			}
+			logger.Error("request completed with errors", "errors", errs)
+			return
+		}
+
+		// 根据状态码选择日志级别
+		switch {
+		case statusCode >= 500:
+			logger.Error("request completed")
+		case statusCode >= 400:
+			logger.Warn("request completed")
+		default:
+			logger.Info("request completed")
 		}
 	}
 }
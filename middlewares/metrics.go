@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute 未匹配路由的请求在指标中使用的路由名称，避免按原始路径产生大量标签
+const unmatchedRoute = "unmatched"
+
+// otherMethod 非标准请求方法在指标中使用的名称，避免客户端任意构造的方法产生大量标签
+const otherMethod = "OTHER"
+
+// knownMethods 在指标中按原样记录的请求方法
+var knownMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodConnect: {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+}
+
+// Metrics 请求指标中间件
+// 按请求方法、路由模板和状态码统计请求数和处理耗时，并记录正在处理的请求数
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		metrics.HTTPRequestsInFlight.Inc()
+		defer metrics.HTTPRequestsInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := metricsMethod(c.Request.Method)
+		status := strconv.Itoa(c.Writer.Status())
+
+		metrics.HTTPRequestsTotal.WithLabelValues(method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// metricsMethod 获取指标中使用的请求方法，非标准方法统一记为 OTHER
+func metricsMethod(method string) string {
+	if _, ok := knownMethods[method]; ok {
+		return method
+	}
+	return otherMethod
+}
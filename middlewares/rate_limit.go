@@ -1,111 +1,92 @@
 package middlewares
 
 import (
-	"net/http"
-	"sync"
+	"math"
+	"strconv"
 	"time"
 
+	"gitee.com/NextEraAbyss/gin-template/internal/metrics"
+	"gitee.com/NextEraAbyss/gin-template/internal/ratelimit"
 	"gitee.com/NextEraAbyss/gin-template/utils"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 )
 
-// IPRateLimiter 基于IP的限流器.
-type IPRateLimiter struct {
-	ips    map[string]*rate.Limiter
-	mu     *sync.RWMutex
-	r      rate.Limit
-	b      int
-	expire time.Duration
-	// 记录每个IP最后访问时间
-	lastSeen map[string]time.Time
-}
-
-// NewIPRateLimiter 创建一个新的IP限流器.
-func NewIPRateLimiter(r rate.Limit, b int) *IPRateLimiter {
-	i := &IPRateLimiter{
-		ips:      make(map[string]*rate.Limiter),
-		mu:       &sync.RWMutex{},
-		r:        r,
-		b:        b,
-		expire:   time.Hour, // 默认1小时后过期
-		lastSeen: make(map[string]time.Time),
-	}
+// RateLimitKeyFunc 从请求中提取限流键，返回空字符串表示不限流
+type RateLimitKeyFunc func(c *gin.Context) string
 
-	// 启动一个协程定期清理过期的限流器
-	go i.cleanupExpired()
-	return i
+// KeyByIP 按客户端IP限流
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
 }
 
-// AddIP 为指定IP创建一个新的速率限制器并添加到映射中
-func (i *IPRateLimiter) AddIP(ip string) *rate.Limiter {
-	i.mu.Lock()
-	defer i.mu.Unlock()
+// KeyByUser 按用户ID限流，需在认证中间件之后使用，未登录时按IP限流
+func KeyByUser(c *gin.Context) string {
+	if id, exists := c.Get("userID"); exists {
+		if userID, ok := id.(uint); ok {
+			return "user:" + strconv.FormatUint(uint64(userID), 10)
+		}
+	}
 
-	limiter := rate.NewLimiter(i.r, i.b)
-	i.ips[ip] = limiter
-	i.lastSeen[ip] = time.Now()
-	return limiter
+	return KeyByIP(c)
 }
 
-// GetLimiter 返回指定IP的速率限制器，如果不存在则创建一个
-func (i *IPRateLimiter) GetLimiter(ip string) *rate.Limiter {
-	i.mu.RLock()
-	limiter, exists := i.ips[ip]
-	i.mu.RUnlock()
+// KeyByAPIKey 按请求头中的API Key限流，未携带时按IP限流
+func KeyByAPIKey(header string) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		if key := c.GetHeader(header); key != "" {
+			return "apikey:" + key
+		}
 
-	if !exists {
-		return i.AddIP(ip)
+		return KeyByIP(c)
 	}
+}
 
-	// 更新最后访问时间
-	i.mu.Lock()
-	i.lastSeen[ip] = time.Now()
-	i.mu.Unlock()
+// RateLimitPolicy 按策略限流的中间件
+// 响应中包含 RateLimit-Policy、RateLimit-Limit、RateLimit-Remaining、RateLimit-Reset 头，
+// 超过限制时返回429并设置 Retry-After；限流器出错时放行请求，避免限流故障导致服务不可用
+func RateLimitPolicy(limiter ratelimit.Limiter, policy ratelimit.Policy, keyFunc RateLimitKeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := keyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
 
-	return limiter
-}
+		result, err := limiter.Allow(c.Request.Context(), policy, key)
+		if err != nil {
+			utils.Warnf("限流检查失败, 策略: %s, 错误: %v", policy.Name, err)
+			c.Next()
+			return
+		}
 
-// cleanupExpired 定期清理过期的IP限制器
-func (i *IPRateLimiter) cleanupExpired() {
-	ticker := time.NewTicker(10 * time.Minute)
-	defer ticker.Stop()
+		c.Header("RateLimit-Policy", policy.String())
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
 
-	for range ticker.C {
-		now := time.Now()
-		i.mu.Lock()
-		for ip, lastTime := range i.lastSeen {
-			// 如果在一段时间内没有请求，则删除该IP的限制器
-			if now.Sub(lastTime) > i.expire {
-				delete(i.ips, ip)
-				delete(i.lastSeen, ip)
-			}
+		if !result.Allowed {
+			metrics.RateLimitRejectedTotal.WithLabelValues(policy.Name).Inc()
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			utils.ResponseError(c, utils.CodeTooManyRequests, "请求过于频繁，请稍后再试")
+			c.Abort()
+			return
 		}
-		i.mu.Unlock()
+
+		c.Next()
 	}
 }
 
-// RateLimit 创建一个限流中间件
+// RateLimit 创建按IP限流的进程内限流中间件
 // 参数:
 // - max: 在time.Duration内允许的最大请求数
 // - duration: 限流的时间窗口
+// 计数只在当前实例有效，多实例部署时应使用 RateLimitPolicy 配合Redis限流器
 func RateLimit(max int, duration time.Duration) gin.HandlerFunc {
-	// 计算速率限制 (请求/秒)
-	limit := rate.Limit(float64(max) / duration.Seconds())
-	limiter := NewIPRateLimiter(limit, max)
+	policy := ratelimit.Policy{Name: "default", Limit: max, Period: duration}
+	return RateLimitPolicy(ratelimit.NewMemoryLimiter(), policy, KeyByIP)
+}
 
-	return func(c *gin.Context) {
-		// 获取客户端IP
-		ip := c.ClientIP()
-		// 获取该IP的限流器
-		ipLimiter := limiter.GetLimiter(ip)
-		// 尝试获取令牌
-		if !ipLimiter.Allow() {
-			// 如果无法获取令牌，返回429状态码
-			utils.ResponseError(c, http.StatusTooManyRequests, "请求过于频繁，请稍后再试")
-			c.Abort()
-			return
-		}
-		c.Next()
-	}
+// ceilSeconds 将时长向上取整为秒
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
 }
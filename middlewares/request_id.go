@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"gitee.com/NextEraAbyss/gin-template/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -22,6 +23,7 @@ func RequestID() gin.HandlerFunc {
 
 		// 将请求ID设置到上下文中
 		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(utils.WithRequestID(c.Request.Context(), requestID))
 
 		// 将请求ID添加到响应头中
 		c.Writer.Header().Set(RequestIDHeaderName, requestID)
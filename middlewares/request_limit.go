@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// rawBodyKey 上下文中保存原始请求体的键，用于多次设置请求体上限时以最后一次为准
+const rawBodyKey = "middlewares.rawBody"
+
+// RouteLimit 路由的请求限制
+type RouteLimit struct {
+	MaxBodyBytes int64         // 请求体最大字节数，0表示使用默认值
+	Timeout      time.Duration // 处理超时时间，0表示使用默认值
+}
+
+// RequestLimits 按路由限制请求体大小和处理时间的中间件
+// routes 的键为 "方法 路由路径"，如 "POST /api/v1/admin/users/import"，未配置的路由使用 defaults。
+// 路由在中间件执行前已匹配完成，因此可以注册为全局中间件，保证在读取请求体的其他中间件之前生效
+func RequestLimits(defaults RouteLimit, routes map[string]RouteLimit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := defaults
+		if override, ok := routes[c.Request.Method+" "+c.FullPath()]; ok {
+			if override.MaxBodyBytes > 0 {
+				limit.MaxBodyBytes = override.MaxBodyBytes
+			}
+			if override.Timeout > 0 {
+				limit.Timeout = override.Timeout
+			}
+		}
+
+		if limit.MaxBodyBytes > 0 && !limitBody(c, limit.MaxBodyBytes) {
+			return
+		}
+
+		if limit.Timeout > 0 {
+			withTimeout(c, limit.Timeout)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// BodyLimit 限制请求体大小的中间件，超过限制时返回413
+// 多次使用时以最后一次设置的上限为准
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limitBody(c, maxBytes) {
+			c.Next()
+		}
+	}
+}
+
+// Timeout 限制处理时间的中间件
+// 超时时间通过请求上下文传递到数据库和Redis调用，处理函数超时后未写入响应时返回504
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		withTimeout(c, timeout)
+	}
+}
+
+// limitBody 设置请求体上限，声明的长度已超过上限时返回413并返回 false
+func limitBody(c *gin.Context, maxBytes int64) bool {
+	if c.Request.ContentLength > maxBytes {
+		utils.ResponseError(c, utils.CodeRequestTooLarge, "")
+		c.Abort()
+		return false
+	}
+
+	raw, exists := c.Get(rawBodyKey)
+	if !exists {
+		raw = c.Request.Body
+		c.Set(rawBodyKey, raw)
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, raw.(io.ReadCloser), maxBytes)
+
+	return true
+}
+
+// withTimeout 使用带超时的请求上下文执行后续处理
+func withTimeout(c *gin.Context, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+		utils.ResponseError(c, utils.CodeRequestTimeout, "")
+		c.Abort()
+	}
+}
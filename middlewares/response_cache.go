@@ -0,0 +1,229 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/cache"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// responseCachePrefix 服务端响应缓存的键前缀
+const responseCachePrefix = "http:"
+
+// CachePolicy 路由的响应缓存策略
+type CachePolicy struct {
+	Group     string        // 缓存分组，用于按分组清理服务端缓存，为空时使用路由路径
+	MaxAge    time.Duration // 客户端缓存有效期，0表示每次都需要使用ETag重新验证
+	Public    bool          // 是否允许代理等共享缓存保存响应，响应因用户而异时应为 false
+	Vary      []string      // 影响响应内容的请求头
+	ServerTTL time.Duration // 服务端缓存完整响应的有效期，0表示不在服务端缓存
+}
+
+// cachedResponse 服务端缓存的响应
+type cachedResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	ETag        string `json:"etag"`
+	Body        []byte `json:"body"`
+}
+
+// ResponseCache 响应缓存中间件
+// 对成功的GET请求根据响应内容生成强ETag，请求携带匹配的 If-None-Match 时返回304，
+// 并按策略设置 Cache-Control 和 Vary 头；配置了 ServerTTL 时在 store 中缓存完整响应，
+// 缓存键包含路由、路径、查询参数和当前用户
+func ResponseCache(store cache.Cache, policy CachePolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		serverCache := store != nil && policy.ServerTTL > 0
+		key := responseCacheKey(c, policy)
+
+		if serverCache {
+			var cached cachedResponse
+			err := store.Get(c.Request.Context(), key, &cached)
+			if err == nil {
+				c.Header("X-Cache", "HIT")
+				writeCachedResponse(c, policy, &cached)
+				c.Abort()
+				return
+			}
+			if !errors.Is(err, cache.ErrCacheMiss) {
+				utils.Warnf("读取响应缓存失败, key: %s, 错误: %v", key, err)
+			}
+		}
+
+		// 缓冲响应内容，处理完成后再计算ETag
+		original := c.Writer
+		writer := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = original
+
+		body := writer.body.Bytes()
+		if writer.status != http.StatusOK {
+			original.WriteHeader(writer.status)
+			original.WriteHeaderNow()
+			_, _ = original.Write(body)
+			return
+		}
+
+		response := &cachedResponse{
+			Status:      writer.status,
+			ContentType: original.Header().Get("Content-Type"),
+			ETag:        computeETag(body),
+			Body:        body,
+		}
+
+		if serverCache {
+			c.Header("X-Cache", "MISS")
+			if err := store.Set(c.Request.Context(), key, response, policy.ServerTTL); err != nil {
+				utils.Warnf("写入响应缓存失败, key: %s, 错误: %v", key, err)
+			}
+		}
+
+		writeCachedResponse(c, policy, response)
+	}
+}
+
+// InvalidateResponseCache 清理指定分组的服务端响应缓存
+func InvalidateResponseCache(ctx context.Context, store cache.Cache, groups ...string) error {
+	var errs []error
+	for _, group := range groups {
+		if err := store.DeleteByPrefix(ctx, responseCachePrefix+group+":"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// writeCachedResponse 写入响应，请求的ETag匹配时返回304
+func writeCachedResponse(c *gin.Context, policy CachePolicy, response *cachedResponse) {
+	header := c.Writer.Header()
+	header.Set("ETag", response.ETag)
+	header.Set("Cache-Control", cacheControl(policy))
+	if len(policy.Vary) > 0 {
+		header.Set("Vary", strings.Join(policy.Vary, ", "))
+	}
+
+	if etagMatches(c.GetHeader("If-None-Match"), response.ETag) {
+		c.Writer.WriteHeader(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	if response.ContentType != "" {
+		header.Set("Content-Type", response.ContentType)
+	}
+	c.Writer.WriteHeader(response.Status)
+	_, _ = c.Writer.Write(response.Body)
+}
+
+// responseCacheKey 生成服务端响应缓存键
+func responseCacheKey(c *gin.Context, policy CachePolicy) string {
+	group := policy.Group
+	if group == "" {
+		group = c.FullPath()
+	}
+
+	var userID uint
+	if id, exists := c.Get("userID"); exists {
+		userID, _ = id.(uint)
+	}
+
+	// Query().Encode() 按参数名排序，参数顺序不同的请求使用相同的缓存
+	raw := fmt.Sprintf("%s?%s#%d", c.Request.URL.Path, c.Request.URL.Query().Encode(), userID)
+	sum := sha256.Sum256([]byte(raw))
+
+	return responseCachePrefix + group + ":" + hex.EncodeToString(sum[:16])
+}
+
+// computeETag 根据响应内容计算强ETag
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches 判断 If-None-Match 是否与ETag匹配，按弱比较规则忽略 W/ 前缀
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cacheControl 根据策略生成 Cache-Control 头
+func cacheControl(policy CachePolicy) string {
+	scope := "private"
+	if policy.Public {
+		scope = "public"
+	}
+
+	if policy.MaxAge <= 0 {
+		return scope + ", no-cache"
+	}
+
+	return fmt.Sprintf("%s, max-age=%d", scope, int(policy.MaxAge.Seconds()))
+}
+
+// bufferedWriter 缓冲响应内容的 ResponseWriter
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader 记录状态码，延迟到缓冲结束后写入
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow 缓冲期间不写入响应头
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+// Write 写入缓冲区
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+// WriteString 写入缓冲区
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// Status 获取状态码
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+// Size 获取已写入的字节数
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+// Written 是否已写入响应内容
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
@@ -49,6 +49,7 @@ func Security() gin.HandlerFunc {
 
 		// 添加额外的安全措施
 		c.Header("X-Permitted-Cross-Domain-Policies", "none")
+		// 默认禁止缓存，允许缓存的路由由 ResponseCache 中间件按策略覆盖
 		c.Header("Cache-Control", "no-store, max-age=0")
 		c.Header("Cross-Origin-Embedder-Policy", "require-corp")
 		c.Header("Cross-Origin-Opener-Policy", "same-origin")
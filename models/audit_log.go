@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// 审计操作类型
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditLog 审计日志模型
+type AuditLog struct {
+	ID         uint      `gorm:"primarykey" json:"id"`                                                // 日志ID
+	CreatedAt  time.Time `gorm:"type:datetime;not null;index" json:"created_at"`                      // 记录时间
+	ActorID    uint      `gorm:"index" json:"actor_id"`                                               // 操作人ID，0表示系统操作
+	RequestID  string    `gorm:"type:varchar(64);index" json:"request_id"`                            // 请求ID
+	Action     string    `gorm:"type:varchar(20);not null" json:"action"`                             // 操作类型
+	EntityType string    `gorm:"type:varchar(50);not null;index:idx_audit_entity" json:"entity_type"` // 实体类型（表名）
+	EntityID   string    `gorm:"type:varchar(64);not null;index:idx_audit_entity" json:"entity_id"`   // 实体ID
+	Changes    string    `gorm:"type:json" json:"changes"`                                            // 变更内容，格式为 {"字段": {"before": 旧值, "after": 新值}}
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
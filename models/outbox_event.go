@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// 发件箱事件状态
+const (
+	OutboxStatusPending   = "pending"   // 待投递
+	OutboxStatusDelivered = "delivered" // 已投递
+	OutboxStatusDead      = "dead"      // 超过最大重试次数，进入死信
+)
+
+// OutboxEvent 发件箱事件模型
+// 领域事件与业务数据在同一事务中写入，由后台投递器异步投递
+type OutboxEvent struct {
+	ID            uint       `gorm:"primarykey" json:"id"`                                                    // 自增ID
+	CreatedAt     time.Time  `gorm:"type:datetime;not null" json:"created_at"`                                // 创建时间
+	UpdatedAt     time.Time  `gorm:"type:datetime;not null" json:"updated_at"`                                // 更新时间
+	EventID       string     `gorm:"type:varchar(36);uniqueIndex;not null" json:"event_id"`                   // 事件唯一ID，供消费方去重
+	EventType     string     `gorm:"type:varchar(100);not null;index" json:"event_type"`                      // 事件类型
+	AggregateType string     `gorm:"type:varchar(50);not null" json:"aggregate_type"`                         // 聚合类型
+	AggregateID   string     `gorm:"type:varchar(64);not null" json:"aggregate_id"`                           // 聚合ID
+	Payload       string     `gorm:"type:json" json:"payload"`                                                // 事件内容
+	RequestID     string     `gorm:"type:varchar(64)" json:"request_id"`                                      // 产生事件的请求ID
+	TraceParent   string     `gorm:"type:varchar(64)" json:"traceparent"`                                     // 产生事件的请求的W3C traceparent，投递时延续链路
+	Status        string     `gorm:"type:varchar(20);not null;index:idx_outbox_dispatch" json:"status"`       // 投递状态
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`                                      // 已尝试投递次数
+	NextAttemptAt time.Time  `gorm:"type:datetime;not null;index:idx_outbox_dispatch" json:"next_attempt_at"` // 下次投递时间
+	LastError     string     `gorm:"type:varchar(1000)" json:"last_error"`                                    // 最近一次投递错误
+	DeliveredAt   *time.Time `json:"delivered_at"`                                                            // 投递成功时间
+}
+
+// TableName 指定表名
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// 隐私请求类型
+const (
+	PrivacyRequestExport  = "export"  // 数据导出
+	PrivacyRequestErasure = "erasure" // 数据删除
+)
+
+// 隐私请求状态
+const (
+	PrivacyStatusPending    = "pending"    // 等待处理（删除请求处于宽限期）
+	PrivacyStatusProcessing = "processing" // 处理中
+	PrivacyStatusCompleted  = "completed"  // 已完成
+	PrivacyStatusFailed     = "failed"     // 处理失败
+	PrivacyStatusCancelled  = "cancelled"  // 已取消
+	PrivacyStatusExpired    = "expired"    // 导出文件已过期
+)
+
+// PrivacyRequest 用户隐私请求模型（数据导出与删除）
+type PrivacyRequest struct {
+	ID          uint       `gorm:"primarykey" json:"id"`                                             // 请求ID
+	CreatedAt   time.Time  `gorm:"type:datetime;not null" json:"created_at"`                         // 创建时间
+	UpdatedAt   time.Time  `gorm:"type:datetime;not null" json:"updated_at"`                         // 更新时间
+	UserID      uint       `gorm:"not null;index" json:"user_id"`                                    // 用户ID
+	Type        string     `gorm:"type:varchar(20);not null" json:"type"`                            // 请求类型
+	Format      string     `gorm:"type:varchar(10)" json:"format"`                                   // 导出格式：zip或json
+	Status      string     `gorm:"type:varchar(20);not null;index:idx_privacy_due" json:"status"`    // 处理状态
+	ScheduledAt time.Time  `gorm:"type:datetime;not null;index:idx_privacy_due" json:"scheduled_at"` // 计划执行时间，删除请求为宽限期结束时间
+	CompletedAt *time.Time `gorm:"type:datetime" json:"completed_at"`                                // 完成时间
+	ClaimedAt   *time.Time `gorm:"type:datetime" json:"-"`                                           // 认领时间，处理超时后重新放回待处理
+	ExpiresAt   *time.Time `gorm:"type:datetime" json:"expires_at"`                                  // 导出文件过期时间
+	Error       string     `gorm:"type:varchar(1000)" json:"error"`                                  // 失败原因
+}
+
+// TableName 指定表名
+func (PrivacyRequest) TableName() string {
+	return "privacy_requests"
+}
+
+// PrivacyExport 数据导出文件，保存在数据库中以便任一实例都能提供下载
+type PrivacyExport struct {
+	ID        uint      `gorm:"primarykey" json:"id"`                        // 文件ID
+	CreatedAt time.Time `gorm:"type:datetime;not null" json:"created_at"`    // 生成时间
+	RequestID uint      `gorm:"not null;uniqueIndex" json:"request_id"`      // 隐私请求ID
+	UserID    uint      `gorm:"not null;index" json:"user_id"`               // 用户ID
+	FileName  string    `gorm:"type:varchar(255);not null" json:"file_name"` // 下载文件名
+	Content   []byte    `gorm:"type:longblob;not null" json:"-"`             // 文件内容
+}
+
+// TableName 指定表名
+func (PrivacyExport) TableName() string {
+	return "privacy_exports"
+}
+
+// PrivacyRequestLog 隐私请求处理记录，用于合规留痕
+type PrivacyRequestLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`                     // 记录ID
+	CreatedAt time.Time `gorm:"type:datetime;not null" json:"created_at"` // 记录时间
+	RequestID uint      `gorm:"not null;index" json:"request_id"`         // 隐私请求ID
+	UserID    uint      `gorm:"not null;index" json:"user_id"`            // 用户ID
+	Step      string    `gorm:"type:varchar(50);not null" json:"step"`    // 处理步骤
+	Detail    string    `gorm:"type:varchar(1000)" json:"detail"`         // 详细信息
+}
+
+// TableName 指定表名
+func (PrivacyRequestLog) TableName() string {
+	return "privacy_request_logs"
+}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/models"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogQueryParams 审计日志查询参数
+type AuditLogQueryParams struct {
+	Page       int       `json:"page"`       // 页码
+	PageSize   int       `json:"pageSize"`   // 每页数量
+	EntityType string    `json:"entityType"` // 实体类型
+	EntityID   string    `json:"entityId"`   // 实体ID
+	ActorID    uint      `json:"actorId"`    // 操作人ID
+	Action     string    `json:"action"`     // 操作类型
+	StartTime  time.Time `json:"startTime"`  // 开始时间
+	EndTime    time.Time `json:"endTime"`    // 结束时间
+}
+
+// AuditLogRepository 审计日志仓库接口
+type AuditLogRepository interface {
+	BaseRepository
+
+	// List 获取审计日志列表
+	List(ctx context.Context, query *AuditLogQueryParams) ([]*models.AuditLog, int64, error)
+
+	// DeleteBefore 删除指定时间之前的审计日志
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// ListByEntity 获取指定实体的全部审计日志
+	ListByEntity(ctx context.Context, entityType, entityID string) ([]*models.AuditLog, error)
+
+	// ListByActor 获取指定用户操作产生的全部审计日志
+	ListByActor(ctx context.Context, actorID uint) ([]*models.AuditLog, error)
+
+	// UpdateChanges 更新审计日志的变更内容，用于隐私数据删除时脱敏
+	UpdateChanges(ctx context.Context, id uint, changes string) error
+}
+
+// auditLogRepository 实现 AuditLogRepository 接口
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// RepositoryName 获取仓库名称
+func (r *auditLogRepository) RepositoryName() string {
+	return "AuditLogRepository"
+}
+
+// NewAuditLogRepository 创建一个新的 AuditLogRepository 实例
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+// List 获取审计日志列表
+func (r *auditLogRepository) List(ctx context.Context, query *AuditLogQueryParams) ([]*models.AuditLog, int64, error) {
+	var logs []*models.AuditLog
+	var total int64
+
+	// 构建查询
+	db := r.db.WithContext(ctx).Model(&models.AuditLog{})
+
+	// 添加查询条件
+	if query.EntityType != "" {
+		db = db.Where("entity_type = ?", query.EntityType)
+	}
+
+	if query.EntityID != "" {
+		db = db.Where("entity_id = ?", query.EntityID)
+	}
+
+	if query.ActorID != 0 {
+		db = db.Where("actor_id = ?", query.ActorID)
+	}
+
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+
+	if !query.StartTime.IsZero() {
+		db = db.Where("created_at >= ?", query.StartTime)
+	}
+
+	if !query.EndTime.IsZero() {
+		db = db.Where("created_at <= ?", query.EndTime)
+	}
+
+	// 获取总数
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页
+	if query.Page > 0 && query.PageSize > 0 {
+		offset := (query.Page - 1) * query.PageSize
+		db = db.Offset(offset).Limit(query.PageSize)
+	}
+
+	// 执行查询，最新的记录在前
+	if err := db.Order("created_at DESC, id DESC").Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+// DeleteBefore 删除指定时间之前的审计日志
+func (r *auditLogRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&models.AuditLog{})
+	return result.RowsAffected, result.Error
+}
+
+// ListByEntity 获取指定实体的全部审计日志
+func (r *auditLogRepository) ListByEntity(ctx context.Context, entityType, entityID string) ([]*models.AuditLog, error) {
+	var logs []*models.AuditLog
+	err := conn(ctx, r.db).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("id").
+		Find(&logs).Error
+	return logs, err
+}
+
+// ListByActor 获取指定用户操作产生的全部审计日志
+func (r *auditLogRepository) ListByActor(ctx context.Context, actorID uint) ([]*models.AuditLog, error) {
+	var logs []*models.AuditLog
+	err := conn(ctx, r.db).Where("actor_id = ?", actorID).Order("id").Find(&logs).Error
+	return logs, err
+}
+
+// UpdateChanges 更新审计日志的变更内容，用于隐私数据删除时脱敏
+func (r *auditLogRepository) UpdateChanges(ctx context.Context, id uint, changes string) error {
+	return conn(ctx, r.db).Model(&models.AuditLog{}).Where("id = ?", id).Update("changes", changes).Error
+}
@@ -0,0 +1,265 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/cache"
+	"gitee.com/NextEraAbyss/gin-template/models"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+// CachedUserRepositoryConfig 用户缓存配置
+type CachedUserRepositoryConfig struct {
+	TTL         time.Duration // 用户数据缓存有效期
+	NegativeTTL time.Duration // 用户不存在结果的缓存有效期
+	Jitter      float64       // 有效期随机浮动比例（0~1），避免大量缓存同时过期
+}
+
+// DefaultCachedUserRepositoryConfig 返回默认用户缓存配置
+func DefaultCachedUserRepositoryConfig() CachedUserRepositoryConfig {
+	return CachedUserRepositoryConfig{
+		TTL:         5 * time.Minute,
+		NegativeTTL: 30 * time.Second,
+		Jitter:      0.1,
+	}
+}
+
+// cachedUser 用户缓存项，Found 为 false 表示用户不存在
+type cachedUser struct {
+	Found bool         `json:"found"`
+	User  *models.User `json:"user,omitempty"`
+	// Password 模型中的密码不参与JSON序列化，单独缓存哈希值供密码校验使用
+	Password string `json:"password,omitempty"`
+}
+
+// cachedUserRepository 带缓存的用户仓库装饰器
+// 按ID、用户名和邮箱查询时优先读取缓存，并发未命中合并为一次数据库查询；
+// 写操作在事务提交后清理相关缓存，事务中的读操作直接查询数据库
+type cachedUserRepository struct {
+	UserRepository
+	cache  cache.Cache
+	config CachedUserRepositoryConfig
+	group  singleflight.Group
+}
+
+// NewCachedUserRepository 创建带缓存的用户仓库
+func NewCachedUserRepository(repo UserRepository, c cache.Cache, config CachedUserRepositoryConfig) UserRepository {
+	defaults := DefaultCachedUserRepositoryConfig()
+	if config.TTL <= 0 {
+		config.TTL = defaults.TTL
+	}
+	if config.NegativeTTL <= 0 {
+		config.NegativeTTL = defaults.NegativeTTL
+	}
+	if config.Jitter <= 0 || config.Jitter >= 1 {
+		config.Jitter = defaults.Jitter
+	}
+
+	return &cachedUserRepository{
+		UserRepository: repo,
+		cache:          c,
+		config:         config,
+	}
+}
+
+// RepositoryName 获取仓库名称
+func (r *cachedUserRepository) RepositoryName() string {
+	return "CachedUserRepository"
+}
+
+// GetByID 根据ID获取用户
+func (r *cachedUserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	return r.load(ctx, userIDCacheKey(id), func(ctx context.Context) (*models.User, error) {
+		return r.UserRepository.GetByID(ctx, id)
+	})
+}
+
+// GetByUsername 根据用户名获取用户
+func (r *cachedUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.load(ctx, usernameCacheKey(username), func(ctx context.Context) (*models.User, error) {
+		return r.UserRepository.GetByUsername(ctx, username)
+	})
+}
+
+// GetByEmail 根据邮箱获取用户
+func (r *cachedUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.load(ctx, emailCacheKey(email), func(ctx context.Context) (*models.User, error) {
+		return r.UserRepository.GetByEmail(ctx, email)
+	})
+}
+
+// Create 创建用户，并清理新用户名和邮箱的不存在缓存
+func (r *cachedUserRepository) Create(ctx context.Context, user *models.User) error {
+	if err := r.UserRepository.Create(ctx, user); err != nil {
+		return err
+	}
+
+	r.invalidateAfterCommit(ctx, user)
+	return nil
+}
+
+// Update 更新用户，并清理新旧用户名和邮箱的缓存
+func (r *cachedUserRepository) Update(ctx context.Context, user *models.User) error {
+	previous, err := r.UserRepository.GetByID(ctx, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if err := r.UserRepository.Update(ctx, user); err != nil {
+		return err
+	}
+
+	r.invalidateAfterCommit(ctx, previous, user)
+	return nil
+}
+
+// Delete 删除用户，并清理相关缓存
+func (r *cachedUserRepository) Delete(ctx context.Context, id uint) error {
+	previous, err := r.UserRepository.GetByID(ctx, id)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if err := r.UserRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidateAfterCommit(ctx, previous, &models.User{ID: id})
+	return nil
+}
+
+// CreateInBatches 分批创建用户，并清理新用户名和邮箱的不存在结果缓存
+func (r *cachedUserRepository) CreateInBatches(ctx context.Context, users []*models.User, batchSize int) error {
+	if err := r.UserRepository.CreateInBatches(ctx, users, batchSize); err != nil {
+		return err
+	}
+
+	r.invalidateAfterCommit(ctx, users...)
+	return nil
+}
+
+// UpdateFields 按ID更新用户的指定字段，并清理新旧用户名和邮箱的缓存
+func (r *cachedUserRepository) UpdateFields(ctx context.Context, user *models.User, fields ...string) error {
+	previous, err := r.UserRepository.GetByID(ctx, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if err := r.UserRepository.UpdateFields(ctx, user, fields...); err != nil {
+		return err
+	}
+
+	r.invalidateAfterCommit(ctx, previous, user)
+	return nil
+}
+
+// load 按缓存旁路模式加载用户
+func (r *cachedUserRepository) load(ctx context.Context, key string, query func(ctx context.Context) (*models.User, error)) (*models.User, error) {
+	// 事务中可能读到未提交的数据，不能读写缓存
+	if inTransaction(ctx) {
+		return query(ctx)
+	}
+
+	var entry cachedUser
+	err := r.cache.Get(ctx, key, &entry)
+	if err == nil {
+		return entry.user()
+	}
+	if !errors.Is(err, cache.ErrCacheMiss) {
+		utils.Warnf("读取用户缓存失败, key: %s, 错误: %v", key, err)
+	}
+
+	// 合并同一个键的并发查询，查询不受单个调用方的上下文取消影响
+	loadCtx := context.WithoutCancel(ctx)
+	result, err, _ := r.group.Do(key, func() (interface{}, error) {
+		user, err := query(loadCtx)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		entry := cachedUser{Found: user != nil, User: user}
+		ttl := r.config.NegativeTTL
+		if user != nil {
+			entry.Password = user.Password
+			ttl = r.jitter(r.config.TTL)
+		}
+
+		if err := r.cache.Set(loadCtx, key, entry, ttl); err != nil {
+			utils.Warnf("写入用户缓存失败, key: %s, 错误: %v", key, err)
+		}
+
+		return entry, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cached := result.(cachedUser)
+	return cached.user()
+}
+
+// invalidateAfterCommit 在事务提交后清理用户的ID、用户名和邮箱缓存
+func (r *cachedUserRepository) invalidateAfterCommit(ctx context.Context, users ...*models.User) {
+	keys := make(map[string]struct{})
+	for _, user := range users {
+		if user == nil {
+			continue
+		}
+		if user.ID != 0 {
+			keys[userIDCacheKey(user.ID)] = struct{}{}
+		}
+		if user.Username != "" {
+			keys[usernameCacheKey(user.Username)] = struct{}{}
+		}
+		if user.Email != "" {
+			keys[emailCacheKey(user.Email)] = struct{}{}
+		}
+	}
+
+	AfterCommit(ctx, func() {
+		for key := range keys {
+			if err := r.cache.Delete(ctx, key); err != nil {
+				utils.Warnf("清理用户缓存失败, key: %s, 错误: %v", key, err)
+			}
+		}
+	})
+}
+
+// jitter 为有效期增加随机浮动
+func (r *cachedUserRepository) jitter(ttl time.Duration) time.Duration {
+	delta := float64(ttl) * r.config.Jitter
+	return ttl + time.Duration(delta*(2*rand.Float64()-1))
+}
+
+// user 从缓存项还原用户，每次返回新的副本，调用方修改不会影响其他调用方
+func (e cachedUser) user() (*models.User, error) {
+	if !e.Found || e.User == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	user := *e.User
+	user.Password = e.Password
+	return &user, nil
+}
+
+// userIDCacheKey 按ID查询用户的缓存键
+func userIDCacheKey(id uint) string {
+	return fmt.Sprintf("user:id:%d", id)
+}
+
+// usernameCacheKey 按用户名查询用户的缓存键
+func usernameCacheKey(username string) string {
+	return "user:username:" + username
+}
+
+// emailCacheKey 按邮箱查询用户的缓存键
+func emailCacheKey(email string) string {
+	return "user:email:" + email
+}
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository 发件箱仓库接口
+type OutboxRepository interface {
+	BaseRepository
+
+	// Add 写入事件，处于事务中时随事务一起提交
+	Add(ctx context.Context, events ...*models.OutboxEvent) error
+
+	// Claim 领取待投递事件，并将其下次投递时间推迟 lease，防止被其他实例重复领取
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error)
+
+	// MarkDelivered 标记事件投递成功
+	MarkDelivered(ctx context.Context, id uint) error
+
+	// MarkFailed 记录投递失败，dead 为 true 时事件进入死信状态不再重试
+	MarkFailed(ctx context.Context, id uint, nextAttemptAt time.Time, lastError string, dead bool) error
+
+	// DeleteDeliveredBefore 删除指定时间之前已投递的事件
+	DeleteDeliveredBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// ReplacePayloads 替换指定聚合所有事件的内容，用于删除事件中的个人信息
+	ReplacePayloads(ctx context.Context, aggregateType, aggregateID, payload string) (int64, error)
+}
+
+// outboxRepository 实现 OutboxRepository 接口
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// RepositoryName 获取仓库名称
+func (r *outboxRepository) RepositoryName() string {
+	return "OutboxRepository"
+}
+
+// NewOutboxRepository 创建一个新的 OutboxRepository 实例
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// Add 写入事件
+func (r *outboxRepository) Add(ctx context.Context, events ...*models.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	return conn(ctx, r.db).Create(events).Error
+}
+
+// Claim 领取待投递事件
+func (r *outboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error) {
+	var events []*models.OutboxEvent
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// SKIP LOCKED 使多个实例可以并发领取不同的事件
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, now).
+			Order("id").
+			Limit(limit).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]uint, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+
+		return tx.Model(&models.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// MarkDelivered 标记事件投递成功
+func (r *outboxRepository) MarkDelivered(ctx context.Context, id uint) error {
+	now := time.Now()
+
+	return conn(ctx, r.db).Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.OutboxStatusDelivered,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"delivered_at": now,
+			"last_error":   "",
+		}).Error
+}
+
+// MarkFailed 记录投递失败
+func (r *outboxRepository) MarkFailed(ctx context.Context, id uint, nextAttemptAt time.Time, lastError string, dead bool) error {
+	status := models.OutboxStatusPending
+	if dead {
+		status = models.OutboxStatusDead
+	}
+
+	return conn(ctx, r.db).Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+		}).Error
+}
+
+// DeleteDeliveredBefore 删除指定时间之前已投递的事件
+func (r *outboxRepository) DeleteDeliveredBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("status = ? AND delivered_at < ?", models.OutboxStatusDelivered, before).
+		Delete(&models.OutboxEvent{})
+
+	return result.RowsAffected, result.Error
+}
+
+// ReplacePayloads 替换指定聚合所有事件的内容
+func (r *outboxRepository) ReplacePayloads(ctx context.Context, aggregateType, aggregateID, payload string) (int64, error) {
+	result := conn(ctx, r.db).Model(&models.OutboxEvent{}).
+		Where("aggregate_type = ? AND aggregate_id = ?", aggregateType, aggregateID).
+		Updates(map[string]interface{}{"payload": payload, "updated_at": time.Now()})
+
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/models"
+
+	"gorm.io/gorm"
+)
+
+// PrivacyRepository 隐私请求仓库接口
+type PrivacyRepository interface {
+	BaseRepository
+
+	// Create 创建隐私请求
+	Create(ctx context.Context, req *models.PrivacyRequest) error
+
+	// Update 保存隐私请求
+	Update(ctx context.Context, req *models.PrivacyRequest) error
+
+	// GetLatest 获取用户最近一次指定类型的隐私请求
+	GetLatest(ctx context.Context, userID uint, reqType string) (*models.PrivacyRequest, error)
+
+	// FindByUser 获取用户指定类型和状态的隐私请求
+	FindByUser(ctx context.Context, userID uint, reqType, status string) ([]*models.PrivacyRequest, error)
+
+	// ListDue 获取已到执行时间的待处理请求
+	ListDue(ctx context.Context, reqType string, now time.Time, limit int) ([]*models.PrivacyRequest, error)
+
+	// ListExpiredExports 获取导出文件已过期的请求
+	ListExpiredExports(ctx context.Context, now time.Time, limit int) ([]*models.PrivacyRequest, error)
+
+	// TransitionStatus 仅当请求处于 from 状态时将其改为 to 状态，返回是否修改成功
+	// 用于多实例部署时认领任务和取消请求
+	TransitionStatus(ctx context.Context, id uint, from, to string) (bool, error)
+
+	// Claim 认领待处理请求：仅当请求处于待处理状态时改为处理中并记录认领时间，返回是否认领成功
+	Claim(ctx context.Context, id uint) (bool, error)
+
+	// RequeueStale 将认领时间早于 before 的处理中请求放回待处理，用于回收实例崩溃后遗留的请求
+	RequeueStale(ctx context.Context, before time.Time) (int64, error)
+
+	// SaveExport 保存数据导出文件
+	SaveExport(ctx context.Context, export *models.PrivacyExport) error
+
+	// GetExport 获取隐私请求的数据导出文件
+	GetExport(ctx context.Context, requestID uint) (*models.PrivacyExport, error)
+
+	// DeleteExport 删除隐私请求的数据导出文件
+	DeleteExport(ctx context.Context, requestID uint) error
+
+	// AddLog 写入处理记录
+	AddLog(ctx context.Context, log *models.PrivacyRequestLog) error
+}
+
+// privacyRepository 实现 PrivacyRepository 接口
+type privacyRepository struct {
+	db *gorm.DB
+}
+
+// RepositoryName 获取仓库名称
+func (r *privacyRepository) RepositoryName() string {
+	return "PrivacyRepository"
+}
+
+// NewPrivacyRepository 创建一个新的 PrivacyRepository 实例
+func NewPrivacyRepository(db *gorm.DB) PrivacyRepository {
+	return &privacyRepository{db: db}
+}
+
+// Create 创建隐私请求
+func (r *privacyRepository) Create(ctx context.Context, req *models.PrivacyRequest) error {
+	return conn(ctx, r.db).Create(req).Error
+}
+
+// Update 保存隐私请求
+func (r *privacyRepository) Update(ctx context.Context, req *models.PrivacyRequest) error {
+	return conn(ctx, r.db).Save(req).Error
+}
+
+// GetLatest 获取用户最近一次指定类型的隐私请求
+func (r *privacyRepository) GetLatest(ctx context.Context, userID uint, reqType string) (*models.PrivacyRequest, error) {
+	var req models.PrivacyRequest
+	err := conn(ctx, r.db).
+		Where("user_id = ? AND type = ?", userID, reqType).
+		Order("id DESC").
+		First(&req).Error
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// FindByUser 获取用户指定类型和状态的隐私请求
+func (r *privacyRepository) FindByUser(ctx context.Context, userID uint, reqType, status string) ([]*models.PrivacyRequest, error) {
+	var reqs []*models.PrivacyRequest
+	err := conn(ctx, r.db).
+		Where("user_id = ? AND type = ? AND status = ?", userID, reqType, status).
+		Order("id").
+		Find(&reqs).Error
+	return reqs, err
+}
+
+// ListDue 获取已到执行时间的待处理请求
+func (r *privacyRepository) ListDue(ctx context.Context, reqType string, now time.Time, limit int) ([]*models.PrivacyRequest, error) {
+	var reqs []*models.PrivacyRequest
+	err := conn(ctx, r.db).
+		Where("type = ? AND status = ? AND scheduled_at <= ?", reqType, models.PrivacyStatusPending, now).
+		Order("scheduled_at, id").
+		Limit(limit).
+		Find(&reqs).Error
+	return reqs, err
+}
+
+// ListExpiredExports 获取导出文件已过期的请求
+func (r *privacyRepository) ListExpiredExports(ctx context.Context, now time.Time, limit int) ([]*models.PrivacyRequest, error) {
+	var reqs []*models.PrivacyRequest
+	err := conn(ctx, r.db).
+		Where("type = ? AND status = ? AND expires_at <= ?", models.PrivacyRequestExport, models.PrivacyStatusCompleted, now).
+		Order("id").
+		Limit(limit).
+		Find(&reqs).Error
+	return reqs, err
+}
+
+// TransitionStatus 仅当请求处于 from 状态时将其改为 to 状态，返回是否修改成功
+func (r *privacyRepository) TransitionStatus(ctx context.Context, id uint, from, to string) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.PrivacyRequest{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{"status": to, "updated_at": time.Now()})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Claim 认领待处理请求
+func (r *privacyRepository) Claim(ctx context.Context, id uint) (bool, error) {
+	now := time.Now()
+
+	result := conn(ctx, r.db).Model(&models.PrivacyRequest{}).
+		Where("id = ? AND status = ?", id, models.PrivacyStatusPending).
+		Updates(map[string]interface{}{"status": models.PrivacyStatusProcessing, "claimed_at": now, "updated_at": now})
+	return result.RowsAffected == 1, result.Error
+}
+
+// RequeueStale 将认领超时的处理中请求放回待处理
+func (r *privacyRepository) RequeueStale(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).Model(&models.PrivacyRequest{}).
+		Where("status = ? AND (claimed_at IS NULL OR claimed_at < ?)", models.PrivacyStatusProcessing, before).
+		Updates(map[string]interface{}{"status": models.PrivacyStatusPending, "claimed_at": nil, "updated_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
+
+// SaveExport 保存数据导出文件，同一请求重新生成时覆盖原文件
+func (r *privacyRepository) SaveExport(ctx context.Context, export *models.PrivacyExport) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("request_id = ?", export.RequestID).Delete(&models.PrivacyExport{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(export).Error
+	})
+}
+
+// GetExport 获取隐私请求的数据导出文件
+func (r *privacyRepository) GetExport(ctx context.Context, requestID uint) (*models.PrivacyExport, error) {
+	var export models.PrivacyExport
+	err := conn(ctx, r.db).Where("request_id = ?", requestID).First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// DeleteExport 删除隐私请求的数据导出文件
+func (r *privacyRepository) DeleteExport(ctx context.Context, requestID uint) error {
+	return conn(ctx, r.db).Where("request_id = ?", requestID).Delete(&models.PrivacyExport{}).Error
+}
+
+// AddLog 写入处理记录
+func (r *privacyRepository) AddLog(ctx context.Context, log *models.PrivacyRequestLog) error {
+	return conn(ctx, r.db).Create(log).Error
+}
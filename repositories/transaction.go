@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txContextKey 事务在上下文中的键
+type txContextKey struct{}
+
+// txHooksContextKey 事务提交后回调在上下文中的键
+type txHooksContextKey struct{}
+
+// txHooks 事务提交后需要执行的回调
+type txHooks struct {
+	fns []func()
+}
+
+// Transactor 事务管理接口
+// 在 fn 中使用传入的 ctx 调用各仓库方法，即可让多个仓库的写操作处于同一事务中
+type Transactor interface {
+	// Transaction 在事务中执行 fn，fn 返回错误时回滚
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// transactor 实现 Transactor 接口
+type transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor 创建事务管理器
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// Transaction 在事务中执行 fn，已处于事务中时复用外层事务
+func (t *transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	hooks := &txHooks{}
+	err := t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txCtx := context.WithValue(ctx, txContextKey{}, tx)
+		return fn(context.WithValue(txCtx, txHooksContextKey{}, hooks))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks.fns {
+		hook()
+	}
+
+	return nil
+}
+
+// AfterCommit 注册在事务提交后执行的回调，事务回滚时不执行
+// 不处于事务中时立即执行，常用于提交后再清理缓存
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(txHooksContextKey{}).(*txHooks); ok {
+		hooks.fns = append(hooks.fns, fn)
+		return
+	}
+
+	fn()
+}
+
+// inTransaction 判断上下文是否处于事务中
+func inTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txContextKey{}).(*gorm.DB)
+	return ok
+}
+
+// conn 获取当前上下文的数据库连接，处于事务中时返回事务连接
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
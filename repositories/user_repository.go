@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 
+	"gitee.com/NextEraAbyss/gin-template/internal/mysql"
 	"gitee.com/NextEraAbyss/gin-template/models"
 
 	"gorm.io/gorm"
@@ -48,6 +49,18 @@ type UserRepository interface {
 
 	// GetByEmail 根据邮箱获取用户
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
+
+	// FindByUsernamesOrEmails 获取用户名或邮箱在给定列表中的用户
+	FindByUsernamesOrEmails(ctx context.Context, usernames, emails []string) ([]*models.User, error)
+
+	// CreateInBatches 分批创建用户
+	CreateInBatches(ctx context.Context, users []*models.User, batchSize int) error
+
+	// UpdateFields 按ID更新用户的指定字段，字段为数据库列名
+	UpdateFields(ctx context.Context, user *models.User, fields ...string) error
+
+	// FindInBatches 按条件分批遍历用户，fn 返回错误时停止遍历
+	FindInBatches(ctx context.Context, query *UserQueryParams, batchSize int, fn func(users []*models.User) error) error
 }
 
 // userRepository 实现 UserRepository 接口
@@ -67,13 +80,13 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 
 // Create 创建用户
 func (r *userRepository) Create(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return conn(ctx, r.db).Create(user).Error
 }
 
 // GetByID 根据ID获取用户
 func (r *userRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(ctx).First(&user, id).Error
+	err := conn(ctx, r.db).First(&user, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -82,12 +95,12 @@ func (r *userRepository) GetByID(ctx context.Context, id uint) (*models.User, er
 
 // Update 更新用户
 func (r *userRepository) Update(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return conn(ctx, r.db).Save(user).Error
 }
 
 // Delete 删除用户
 func (r *userRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&models.User{}, id).Error
+	return conn(ctx, r.db).Delete(&models.User{}, id).Error
 }
 
 // List 获取用户列表
@@ -97,19 +110,7 @@ func (r *userRepository) List(ctx context.Context, query *UserQueryParams) ([]*m
 	var order string
 
 	// 构建查询
-	db := r.db.WithContext(ctx).Model(&models.User{})
-
-	// 添加查询条件
-	if query.Keyword != "" {
-		db = db.Where("username LIKE ? OR email LIKE ? OR nickname LIKE ?",
-			"%"+query.Keyword+"%",
-			"%"+query.Keyword+"%",
-			"%"+query.Keyword+"%")
-	}
-
-	if query.Status != 0 {
-		db = db.Where("status = ?", query.Status)
-	}
+	db := r.filter(conn(ctx, r.db).Model(&models.User{}), query)
 
 	// 获取总数
 	if err := db.Count(&total).Error; err != nil {
@@ -144,7 +145,7 @@ func (r *userRepository) List(ctx context.Context, query *UserQueryParams) ([]*m
 // GetByUsername 根据用户名获取用户
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
+	err := conn(ctx, r.db).Where("username = ?", username).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
@@ -154,9 +155,66 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*m
 // GetByEmail 根据邮箱获取用户
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	err := conn(ctx, r.db).Where("email = ?", email).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+// FindByUsernamesOrEmails 获取用户名或邮箱在给定列表中的用户
+func (r *userRepository) FindByUsernamesOrEmails(ctx context.Context, usernames, emails []string) ([]*models.User, error) {
+	var users []*models.User
+	if len(usernames) == 0 && len(emails) == 0 {
+		return users, nil
+	}
+
+	err := conn(ctx, r.db).
+		Where("username IN ?", usernames).
+		Or("email IN ?", emails).
+		Find(&users).Error
+	return users, err
+}
+
+// CreateInBatches 分批创建用户
+func (r *userRepository) CreateInBatches(ctx context.Context, users []*models.User, batchSize int) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	return mysql.NewQueryBuilder(conn(ctx, r.db)).
+		WithContext(ctx).
+		BatchCreate(users, batchSize)
+}
+
+// UpdateFields 按ID更新用户的指定字段，字段为数据库列名
+func (r *userRepository) UpdateFields(ctx context.Context, user *models.User, fields ...string) error {
+	return conn(ctx, r.db).Model(user).Select(fields).Updates(user).Error
+}
+
+// FindInBatches 按条件分批遍历用户，fn 返回错误时停止遍历
+func (r *userRepository) FindInBatches(ctx context.Context, query *UserQueryParams, batchSize int, fn func(users []*models.User) error) error {
+	var users []*models.User
+
+	db := r.filter(conn(ctx, r.db).Model(&models.User{}), query)
+
+	return db.FindInBatches(&users, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(users)
+	}).Error
+}
+
+// filter 添加用户查询条件
+func (r *userRepository) filter(db *gorm.DB, query *UserQueryParams) *gorm.DB {
+	if query.Keyword != "" {
+		db = db.Where("username LIKE ? OR email LIKE ? OR nickname LIKE ?",
+			"%"+query.Keyword+"%",
+			"%"+query.Keyword+"%",
+			"%"+query.Keyword+"%")
+	}
+
+	if query.Status != 0 {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	return db
+}
@@ -1,11 +1,17 @@
 package routes
 
 import (
+	"context"
 	"time"
 
+	"gitee.com/NextEraAbyss/gin-template/config"
 	"gitee.com/NextEraAbyss/gin-template/internal/container"
+	"gitee.com/NextEraAbyss/gin-template/internal/metrics"
+	"gitee.com/NextEraAbyss/gin-template/internal/mysql"
+	"gitee.com/NextEraAbyss/gin-template/internal/outbox"
+	internalRedis "gitee.com/NextEraAbyss/gin-template/internal/redis"
 	"gitee.com/NextEraAbyss/gin-template/middlewares"
-	"gitee.com/NextEraAbyss/gin-template/models"
+	"gitee.com/NextEraAbyss/gin-template/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	swaggerFiles "github.com/swaggo/files"
@@ -13,48 +19,131 @@ import (
 	"gorm.io/gorm"
 )
 
+// userResponseCacheGroup 用户查询接口的响应缓存分组
+const userResponseCacheGroup = "users"
+
 // SetupRoutes 配置所有路由
 // 极简版本 - 只保留用户相关功能
-func SetupRoutes(router *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
-	err := db.AutoMigrate(
-		&models.User{}, // 只保留用户表
-	)
-	if err != nil {
-		panic("数据库迁移失败: " + err.Error())
+func SetupRoutes(router *gin.Engine, cfg *config.Config, db *gorm.DB, redisClient *redis.Client) {
+	// 迁移失败时不退出，由就绪和启动探针报告未就绪，并在后台重试
+	if err := migrate(db, redisClient); err != nil {
+		utils.Errorf("数据库迁移失败，将在后台重试: %v", err)
+		go retryMigrate(db, redisClient, 10*time.Second)
 	}
 
 	// 创建依赖注入容器
-	newContainer := container.NewContainer(db, redisClient)
+	newContainer := container.NewContainer(cfg, db, redisClient)
+	newContainer.InitHealth()
+	newContainer.InitCache()
+	newContainer.InitRateLimit()
+	newContainer.InitIdempotency()
 	newContainer.InitRepositories()
+	newContainer.InitEvents()
 	newContainer.InitServices()
 	newContainer.InitControllers()
 
+	// 启动审计日志定期清理任务，多实例部署时只在主节点上执行
+	if cfg.Audit.RetentionDays > 0 {
+		runAsLeader(redisClient, "audit-log-retention", func(ctx context.Context) {
+			newContainer.Services.AuditLog.RunRetention(ctx, time.Hour)
+		})
+	}
+
+	// 启动连接池监控
+	mysql.NewQueryBuilder(db).MonitorPool(context.Background(), redisClient)
+
+	// 启动发件箱投递器
+	go newContainer.Events.Relay.Run(context.Background())
+
+	// 启动隐私请求处理任务（数据导出与到期删除）
+	go newContainer.Services.Privacy.Run(context.Background(),
+		time.Duration(cfg.Privacy.WorkerIntervalSeconds)*time.Second)
+
 	// Swagger 文档路由
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// 健康检查路由，不经过限流等全局中间件
+	healthController := newContainer.GetHealthController()
+	router.GET("/livez", healthController.Livez)
+	router.GET("/readyz", healthController.Readyz)
+	router.GET("/startupz", healthController.Startupz)
+
+	if cfg.Metrics.Enabled {
+		registerPoolMetrics(db, redisClient)
+	}
+
 	// 保留必要的全局中间件
-	router.Use(middlewares.RequestID())                  // 请求ID中间件，便于追踪请求
-	router.Use(middlewares.Logger())                     // 日志中间件
-	router.Use(middlewares.Recovery())                   // 恢复中间件
-	router.Use(middlewares.ErrorHandler())               // 错误处理中间件
-	router.Use(middlewares.CorsMiddleware())             // CORS中间件
-	router.Use(middlewares.Security())                   // 安全中间件，添加安全相关HTTP头
-	router.Use(middlewares.RateLimit(1000, time.Minute)) // 限流中间件，每分钟最多1000个请求
+	router.Use(middlewares.RequestID())    // 请求ID中间件，便于追踪请求
+	router.Use(middlewares.Tracing())      // 链路追踪中间件，延续请求中的 traceparent
+	router.Use(middlewares.Metrics())      // 指标中间件，按路由模板统计请求数和耗时
+	router.Use(middlewares.Logger())       // 日志中间件
+	router.Use(middlewares.Recovery())     // 恢复中间件
+	router.Use(middlewares.ErrorHandler()) // 错误处理中间件
+	router.Use(corsMiddleware(cfg))        // CORS中间件，按路径选择跨域策略
+	router.Use(middlewares.Security())     // 安全中间件，添加安全相关HTTP头
+	router.Use(requestLimits(cfg))         // 请求限制中间件，按路由限制请求体大小和处理时间
+
+	// 响应压缩中间件，根据 Accept-Encoding 协商压缩算法
+	if cfg.Compress.Enabled {
+		router.Use(middlewares.Compress(middlewares.CompressConfig{
+			Encodings:    cfg.Compress.Encodings,
+			MinSize:      cfg.Compress.MinSize,
+			ContentTypes: cfg.Compress.ContentTypes,
+		}))
+	}
+
+	// 限流中间件，按IP限流，Redis可用时多实例共享计数
+	router.Use(newContainer.RateLimitMiddleware(container.RateLimitGlobal, middlewares.KeyByIP))
+
+	// 未配置独立监听地址时，指标接口与业务接口使用同一端口，经过全局中间件
+	if cfg.Metrics.Enabled && cfg.Metrics.Addr == "" {
+		router.GET(cfg.Metrics.Path, gin.WrapH(metrics.Handler()))
+	}
 
 	// API路由组
 	api := router.Group("/api/v1")
 
+	// 公开的用户查询接口使用ETag和服务端响应缓存，用户变更事件投递后清理缓存
+	userCache := middlewares.ResponseCache(newContainer.Cache, middlewares.CachePolicy{
+		Group:     userResponseCacheGroup,
+		MaxAge:    time.Duration(cfg.Cache.HTTPMaxAgeSeconds) * time.Second,
+		Public:    true,
+		Vary:      []string{"Accept-Encoding"},
+		ServerTTL: time.Duration(cfg.Cache.HTTPServerTTLSeconds) * time.Second,
+	})
+	newContainer.Events.Bus.Subscribe(outbox.AllEvents, func(ctx context.Context, event outbox.Event) error {
+		if event.AggregateType != "user" {
+			return nil
+		}
+		return middlewares.InvalidateResponseCache(ctx, newContainer.Cache, userResponseCacheGroup)
+	})
+
 	// 用户相关路由
 	users := api.Group("/users")
-	users.GET("", newContainer.GetUserController().List)    // 获取用户列表
-	users.GET("/:id", newContainer.GetUserController().Get) // 获取单个用户
+	users.GET("", userCache, newContainer.GetUserController().List)    // 获取用户列表
+	users.GET("/:id", userCache, newContainer.GetUserController().Get) // 获取单个用户
 
 	// 需要认证的用户路由
 	userAuth := users.Group("")
-	userAuth.Use(middlewares.AuthMiddleware())
+	userAuth.Use(middlewares.AuthMiddleware(), newContainer.RateLimitMiddleware(container.RateLimitUser, middlewares.KeyByUser),
+		newContainer.IdempotencyMiddleware())
 	userAuth.PUT("/:id", newContainer.GetUserController().Update)                      // 更新用户信息
 	userAuth.DELETE("/:id", newContainer.GetUserController().Delete)                   // 删除用户
 	userAuth.POST("/change-password", newContainer.GetUserController().ChangePassword) // 修改密码
+
+	// 个人数据导出与删除
+	userAuth.GET("/me/export", newContainer.GetPrivacyController().Export)                  // 申请导出个人数据
+	userAuth.GET("/me/export/download", newContainer.GetPrivacyController().DownloadExport) // 下载个人数据导出文件
+	userAuth.POST("/me/erase", newContainer.GetPrivacyController().Erase)                   // 申请删除个人数据
+	userAuth.DELETE("/me/erase", newContainer.GetPrivacyController().CancelErase)           // 取消删除个人数据
+
+	// 管理相关路由（需要管理员权限）
+	admin := api.Group("/admin")
+	admin.Use(middlewares.AuthMiddleware(), newContainer.AdminMiddleware(), newContainer.RateLimitMiddleware(container.RateLimitAdmin, middlewares.KeyByUser),
+		newContainer.IdempotencyMiddleware())
+	admin.GET("/audit-logs", newContainer.GetAuditLogController().List)  // 查询审计日志
+	admin.POST("/users/import", newContainer.GetUserController().Import) // 批量导入用户
+	admin.GET("/users/export", newContainer.GetUserController().Export)  // 导出用户
 }
 
 // RegisterRoutes 注册所有路由.
@@ -64,3 +153,107 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 func RegisterRoutes(router *gin.Engine) {
 	// 这个函数可以保留为空，或者用于其他不需要数据库连接的路由注册.
 }
+
+// migrate 迁移数据库表结构
+// 多实例同时启动时通过分布式锁保证只有一个实例执行迁移，其他实例等待迁移完成
+func migrate(db *gorm.DB, redisClient *redis.Client) error {
+	run := func(ctx context.Context) error {
+		return mysql.Migrate(ctx, db)
+	}
+
+	if redisClient == nil {
+		return run(context.Background())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	lockConfig := internalRedis.DefaultLockConfig()
+	lockConfig.RetryInterval = time.Second
+	return internalRedis.WithLock(ctx, redisClient, "migrations", lockConfig, run)
+}
+
+// retryMigrate 定期重试数据库迁移直到成功
+func retryMigrate(db *gorm.DB, redisClient *redis.Client, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := migrate(db, redisClient); err != nil {
+			utils.Errorf("数据库迁移重试失败: %v", err)
+			continue
+		}
+		utils.Infof("数据库迁移完成")
+		return
+	}
+}
+
+// runAsLeader 在后台执行任务，Redis可用时通过选主保证同一时刻只有一个实例执行
+func runAsLeader(redisClient *redis.Client, name string, fn func(ctx context.Context)) {
+	if redisClient == nil {
+		go fn(context.Background())
+		return
+	}
+
+	election := internalRedis.NewLeaderElection(redisClient, name, internalRedis.DefaultElectionConfig())
+	go election.Run(context.Background(), fn)
+}
+
+// registerPoolMetrics 注册数据库和Redis连接池指标
+func registerPoolMetrics(db *gorm.DB, redisClient *redis.Client) {
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = metrics.RegisterDBStats("mysql", sqlDB)
+	}
+	if err != nil {
+		utils.Warnf("注册数据库连接池指标失败: %v", err)
+	}
+
+	if redisClient == nil {
+		return
+	}
+	if err := metrics.RegisterRedisPool(redisClient); err != nil {
+		utils.Warnf("注册Redis连接池指标失败: %v", err)
+	}
+}
+
+// requestLimits 根据配置创建请求限制中间件，文件上传和导入导出接口使用更宽松的限制
+func requestLimits(cfg *config.Config) gin.HandlerFunc {
+	defaults := middlewares.RouteLimit{
+		MaxBodyBytes: int64(cfg.Request.MaxBodyKB) << 10,
+		Timeout:      time.Duration(cfg.Request.TimeoutSeconds) * time.Second,
+	}
+	long := time.Duration(cfg.Request.LongTimeoutSeconds) * time.Second
+
+	return middlewares.RequestLimits(defaults, map[string]middlewares.RouteLimit{
+		"POST /api/v1/admin/users/import":      {MaxBodyBytes: int64(cfg.Request.UploadMaxBodyMB) << 20, Timeout: long},
+		"GET /api/v1/admin/users/export":       {Timeout: long},
+		"GET /api/v1/users/me/export/download": {Timeout: long},
+	})
+}
+
+// corsMiddleware 根据配置创建跨域中间件，管理接口可以配置单独的允许来源
+func corsMiddleware(cfg *config.Config) gin.HandlerFunc {
+	policy := middlewares.DefaultCORSPolicy()
+	policy.AllowOrigins = cfg.CORS.AllowOrigins
+	policy.AllowCredentials = cfg.CORS.AllowCredentials
+	policy.MaxAge = time.Duration(cfg.CORS.MaxAgeSeconds) * time.Second
+	if len(cfg.CORS.AllowMethods) > 0 {
+		policy.AllowMethods = cfg.CORS.AllowMethods
+	}
+	if len(cfg.CORS.AllowHeaders) > 0 {
+		policy.AllowHeaders = cfg.CORS.AllowHeaders
+	}
+	if len(cfg.CORS.ExposeHeaders) > 0 {
+		policy.ExposeHeaders = cfg.CORS.ExposeHeaders
+	}
+
+	var rules []middlewares.CORSRule
+	if len(cfg.CORS.AdminAllowOrigins) > 0 {
+		adminPolicy := policy
+		adminPolicy.AllowOrigins = cfg.CORS.AdminAllowOrigins
+		rules = append(rules, middlewares.CORSRule{PathPrefix: "/api/v1/admin", Policy: adminPolicy})
+	}
+
+	return middlewares.CORS(policy, rules...)
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/models"
+	"gitee.com/NextEraAbyss/gin-template/repositories"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"gitee.com/NextEraAbyss/gin-template/validation"
+)
+
+// AuditLogService 审计日志服务接口
+type AuditLogService interface {
+	BaseService
+
+	// List 获取审计日志列表
+	List(ctx context.Context, query *validation.AuditLogQueryDTO) ([]models.AuditLog, int64, error)
+
+	// PurgeExpired 清理超过保留期的审计日志
+	PurgeExpired(ctx context.Context) (int64, error)
+
+	// RunRetention 定期清理过期审计日志，直到上下文取消
+	RunRetention(ctx context.Context, interval time.Duration)
+}
+
+// auditLogService 审计日志服务实现
+type auditLogService struct {
+	repo      repositories.AuditLogRepository
+	retention time.Duration
+}
+
+// ServiceName 获取服务名称
+func (s *auditLogService) ServiceName() string {
+	return "AuditLogService"
+}
+
+// NewAuditLogService 创建审计日志服务实例
+// retention 为审计日志保留时长，小于等于0表示永久保留
+func NewAuditLogService(repo repositories.AuditLogRepository, retention time.Duration) AuditLogService {
+	return &auditLogService{
+		repo:      repo,
+		retention: retention,
+	}
+}
+
+// List 获取审计日志列表
+func (s *auditLogService) List(ctx context.Context, query *validation.AuditLogQueryDTO) ([]models.AuditLog, int64, error) {
+	// 转换为仓库层查询对象
+	repoQuery := &repositories.AuditLogQueryParams{
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		EntityType: query.EntityType,
+		EntityID:   query.EntityID,
+		ActorID:    query.ActorID,
+		Action:     query.Action,
+		StartTime:  query.StartTime,
+		EndTime:    query.EndTime,
+	}
+
+	logs, total, err := s.repo.List(ctx, repoQuery)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 转换为非指针切片
+	items := make([]models.AuditLog, 0, len(logs))
+
+	for _, log := range logs {
+		if log != nil {
+			items = append(items, *log)
+		}
+	}
+
+	return items, total, nil
+}
+
+// PurgeExpired 清理超过保留期的审计日志
+func (s *auditLogService) PurgeExpired(ctx context.Context) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+
+	return s.repo.DeleteBefore(ctx, time.Now().Add(-s.retention))
+}
+
+// RunRetention 定期清理过期审计日志，直到上下文取消
+func (s *auditLogService) RunRetention(ctx context.Context, interval time.Duration) {
+	if s.retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.PurgeExpired(ctx)
+			if err != nil {
+				utils.Errorf("清理过期审计日志失败: %v", err)
+				continue
+			}
+
+			if deleted > 0 {
+				utils.Infof("已清理过期审计日志 %d 条", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/audit"
+	"gitee.com/NextEraAbyss/gin-template/internal/privacy"
+	"gitee.com/NextEraAbyss/gin-template/models"
+	"gitee.com/NextEraAbyss/gin-template/repositories"
+)
+
+// erasedValue 脱敏后的占位值
+const erasedValue = "[erased]"
+
+// userPrivacyModule 用户模块的隐私数据处理器
+type userPrivacyModule struct {
+	users UserService
+}
+
+// NewUserPrivacyModule 创建用户模块的隐私数据处理器
+func NewUserPrivacyModule(users UserService) privacy.Module {
+	return &userPrivacyModule{users: users}
+}
+
+// Name 模块名称
+func (m *userPrivacyModule) Name() string {
+	return "users"
+}
+
+// Export 导出用户资料，不包含密码
+func (m *userPrivacyModule) Export(ctx context.Context, userID uint) (interface{}, error) {
+	return m.users.GetByID(ctx, userID)
+}
+
+// Erase 匿名化用户资料，用户已不存在时视为已完成
+func (m *userPrivacyModule) Erase(ctx context.Context, userID uint) error {
+	if err := m.users.Anonymize(ctx, userID); err != nil && !errors.Is(err, ErrUserNotFound) {
+		return err
+	}
+	return nil
+}
+
+// auditLogPrivacyModule 审计日志模块的隐私数据处理器
+// 审计日志需要保留用于合规，删除时只脱敏变更内容中的字段值
+type auditLogPrivacyModule struct {
+	repo repositories.AuditLogRepository
+}
+
+// NewAuditLogPrivacyModule 创建审计日志模块的隐私数据处理器
+// 需要注册在用户模块之后，以便脱敏匿名化操作本身产生的审计日志
+func NewAuditLogPrivacyModule(repo repositories.AuditLogRepository) privacy.Module {
+	return &auditLogPrivacyModule{repo: repo}
+}
+
+// Name 模块名称
+func (m *auditLogPrivacyModule) Name() string {
+	return "audit_logs"
+}
+
+// Export 导出用户资料的变更记录和用户本人的操作记录
+func (m *auditLogPrivacyModule) Export(ctx context.Context, userID uint) (interface{}, error) {
+	changes, err := m.repo.ListByEntity(ctx, models.User{}.TableName(), strconv.FormatUint(uint64(userID), 10))
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := m.repo.ListByActor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 用户操作的记录可能属于其他用户（如管理员修改其他用户资料），只导出操作了哪些字段，不导出字段值
+	actions := make([]auditActionExport, 0, len(logs))
+	for _, log := range logs {
+		actions = append(actions, auditActionExport{
+			ID:         log.ID,
+			CreatedAt:  log.CreatedAt,
+			Action:     log.Action,
+			EntityType: log.EntityType,
+			EntityID:   log.EntityID,
+			Fields:     changedFields(log.Changes),
+		})
+	}
+
+	return map[string]interface{}{
+		"profile_changes": changes,
+		"actions":         actions,
+	}, nil
+}
+
+// auditActionExport 导出的用户操作记录，不包含变更前后的字段值
+type auditActionExport struct {
+	ID         uint      `json:"id"`          // 日志ID
+	CreatedAt  time.Time `json:"created_at"`  // 操作时间
+	Action     string    `json:"action"`      // 操作类型
+	EntityType string    `json:"entity_type"` // 实体类型（表名）
+	EntityID   string    `json:"entity_id"`   // 实体ID
+	Fields     []string  `json:"fields"`      // 变更的字段
+}
+
+// changedFields 获取审计日志中变更的字段名，按名称排序
+func changedFields(data string) []string {
+	var changes map[string]audit.Change
+	if data == "" || json.Unmarshal([]byte(data), &changes) != nil {
+		return []string{}
+	}
+
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Erase 将用户资料变更记录中的字段值替换为占位值，保留变更了哪些字段
+func (m *auditLogPrivacyModule) Erase(ctx context.Context, userID uint) error {
+	logs, err := m.repo.ListByEntity(ctx, models.User{}.TableName(), strconv.FormatUint(uint64(userID), 10))
+	if err != nil {
+		return err
+	}
+
+	for _, log := range logs {
+		var changes map[string]audit.Change
+		if log.Changes == "" || json.Unmarshal([]byte(log.Changes), &changes) != nil {
+			// 无法解析的变更内容整体清除
+			changes = map[string]audit.Change{}
+		}
+
+		for field, change := range changes {
+			if change.Before != nil {
+				change.Before = erasedValue
+			}
+			if change.After != nil {
+				change.After = erasedValue
+			}
+			changes[field] = change
+		}
+
+		data, err := json.Marshal(changes)
+		if err != nil {
+			return err
+		}
+
+		if err := m.repo.UpdateChanges(ctx, log.ID, string(data)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// outboxPrivacyModule 发件箱模块的隐私数据处理器
+// 用户事件内容包含用户名、邮箱等个人信息，删除时只保留用户ID
+type outboxPrivacyModule struct {
+	repo repositories.OutboxRepository
+}
+
+// NewOutboxPrivacyModule 创建发件箱模块的隐私数据处理器
+// 需要注册在用户模块之后，以便同时处理匿名化操作本身产生的事件
+func NewOutboxPrivacyModule(repo repositories.OutboxRepository) privacy.Module {
+	return &outboxPrivacyModule{repo: repo}
+}
+
+// Name 模块名称
+func (m *outboxPrivacyModule) Name() string {
+	return "outbox_events"
+}
+
+// Erase 将用户事件的内容替换为只包含用户ID，尚未投递的事件随后也只投递用户ID
+func (m *outboxPrivacyModule) Erase(ctx context.Context, userID uint) error {
+	data, err := json.Marshal(UserIDEventPayload{ID: userID})
+	if err != nil {
+		return err
+	}
+
+	_, err = m.repo.ReplacePayloads(ctx, userAggregateType, strconv.FormatUint(uint64(userID), 10), string(data))
+	return err
+}
@@ -0,0 +1,490 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/privacy"
+	"gitee.com/NextEraAbyss/gin-template/models"
+	"gitee.com/NextEraAbyss/gin-template/repositories"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"gorm.io/gorm"
+)
+
+// 隐私请求处理步骤，写入合规记录
+const (
+	PrivacyStepExportRequested  = "export_requested"
+	PrivacyStepExportStarted    = "export_started"
+	PrivacyStepModuleExported   = "module_exported"
+	PrivacyStepExportCompleted  = "export_completed"
+	PrivacyStepExportFailed     = "export_failed"
+	PrivacyStepExportDownloaded = "export_downloaded"
+	PrivacyStepExportExpired    = "export_expired"
+	PrivacyStepErasureRequested = "erasure_requested"
+	PrivacyStepErasureCancelled = "erasure_cancelled"
+	PrivacyStepErasureStarted   = "erasure_started"
+	PrivacyStepModuleErased     = "module_erased"
+	PrivacyStepErasureCompleted = "erasure_completed"
+	PrivacyStepErasureFailed    = "erasure_failed"
+)
+
+// 隐私服务错误
+var (
+	ErrPrivacyExportNotReady  = errors.New("数据导出文件尚未生成或已过期")
+	ErrPrivacyErasureNotFound = errors.New("没有可取消的数据删除请求")
+)
+
+// privacyBatchSize 每轮处理的请求数
+const privacyBatchSize = 10
+
+// privacyErrorLength 失败原因和处理记录详情的最大字符数，与字段长度一致
+const privacyErrorLength = 1000
+
+// PrivacyConfig 隐私服务配置
+type PrivacyConfig struct {
+	ExportTTL          time.Duration // 导出文件有效期
+	ErasureGracePeriod time.Duration // 数据删除宽限期，期间可以取消
+	RetryInterval      time.Duration // 处理失败后的重试间隔
+	ClaimTimeout       time.Duration // 认领后的处理时限，超时的请求视为处理实例已退出，重新放回待处理
+}
+
+// PrivacyService 用户隐私数据服务接口（数据导出与删除）
+type PrivacyService interface {
+	BaseService
+
+	// RequestExport 申请导出用户数据
+	// 已有处理中或未过期的导出时直接返回该请求
+	RequestExport(ctx context.Context, userID uint, format string) (*models.PrivacyRequest, error)
+
+	// GetExport 获取用户可下载的导出文件，并记录下载行为
+	GetExport(ctx context.Context, userID uint) (*models.PrivacyExport, error)
+
+	// RequestErasure 申请删除用户数据，宽限期结束后执行
+	// 已有等待中的删除请求时直接返回该请求
+	RequestErasure(ctx context.Context, userID uint) (*models.PrivacyRequest, error)
+
+	// CancelErasure 在宽限期内取消数据删除请求
+	CancelErasure(ctx context.Context, userID uint) (*models.PrivacyRequest, error)
+
+	// ProcessDue 处理到期的导出和删除请求，并清理过期的导出文件
+	ProcessDue(ctx context.Context)
+
+	// Run 定期处理隐私请求，直到上下文取消
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// privacyService 隐私数据服务实现
+type privacyService struct {
+	repo       repositories.PrivacyRepository
+	transactor repositories.Transactor
+	registry   *privacy.Registry
+	config     PrivacyConfig
+	wake       chan struct{}
+}
+
+// ServiceName 获取服务名称
+func (s *privacyService) ServiceName() string {
+	return "PrivacyService"
+}
+
+// NewPrivacyService 创建隐私数据服务实例
+// 导出和删除由 registry 中注册的各模块处理器完成
+func NewPrivacyService(repo repositories.PrivacyRepository, transactor repositories.Transactor, registry *privacy.Registry, config PrivacyConfig) PrivacyService {
+	if config.ExportTTL <= 0 {
+		config.ExportTTL = 24 * time.Hour
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = time.Hour
+	}
+	if config.ClaimTimeout <= 0 {
+		config.ClaimTimeout = 30 * time.Minute
+	}
+
+	return &privacyService{
+		repo:       repo,
+		transactor: transactor,
+		registry:   registry,
+		config:     config,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// RequestExport 申请导出用户数据
+func (s *privacyService) RequestExport(ctx context.Context, userID uint, format string) (*models.PrivacyRequest, error) {
+	if format == "" {
+		format = privacy.FormatZIP
+	}
+
+	latest, err := s.repo.GetLatest(ctx, userID, models.PrivacyRequestExport)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if latest != nil && latest.Format == format {
+		switch latest.Status {
+		case models.PrivacyStatusPending, models.PrivacyStatusProcessing:
+			return latest, nil
+		case models.PrivacyStatusCompleted:
+			if latest.ExpiresAt != nil && latest.ExpiresAt.After(time.Now()) {
+				return latest, nil
+			}
+		}
+	}
+
+	req := &models.PrivacyRequest{
+		UserID:      userID,
+		Type:        models.PrivacyRequestExport,
+		Format:      format,
+		Status:      models.PrivacyStatusPending,
+		ScheduledAt: time.Now(),
+	}
+
+	err = s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, req); err != nil {
+			return err
+		}
+
+		return s.record(ctx, req, PrivacyStepExportRequested, "格式: "+format)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify()
+	return req, nil
+}
+
+// GetExport 获取用户可下载的导出文件，并记录下载行为
+func (s *privacyService) GetExport(ctx context.Context, userID uint) (*models.PrivacyExport, error) {
+	reqs, err := s.repo.FindByUser(ctx, userID, models.PrivacyRequestExport, models.PrivacyStatusCompleted)
+	if err != nil {
+		return nil, err
+	}
+
+	// 取最近一次未过期的导出
+	for i := len(reqs) - 1; i >= 0; i-- {
+		req := reqs[i]
+		if req.ExpiresAt == nil || !req.ExpiresAt.After(time.Now()) {
+			continue
+		}
+
+		export, err := s.repo.GetExport(ctx, req.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.record(ctx, req, PrivacyStepExportDownloaded, ""); err != nil {
+			return nil, err
+		}
+		return export, nil
+	}
+
+	return nil, ErrPrivacyExportNotReady
+}
+
+// RequestErasure 申请删除用户数据，宽限期结束后执行
+func (s *privacyService) RequestErasure(ctx context.Context, userID uint) (*models.PrivacyRequest, error) {
+	pending, err := s.repo.FindByUser(ctx, userID, models.PrivacyRequestErasure, models.PrivacyStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		return pending[0], nil
+	}
+
+	req := &models.PrivacyRequest{
+		UserID:      userID,
+		Type:        models.PrivacyRequestErasure,
+		Status:      models.PrivacyStatusPending,
+		ScheduledAt: time.Now().Add(s.config.ErasureGracePeriod),
+	}
+
+	err = s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, req); err != nil {
+			return err
+		}
+
+		return s.record(ctx, req, PrivacyStepErasureRequested,
+			"计划执行时间: "+req.ScheduledAt.Format("2006-01-02 15:04:05"))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.config.ErasureGracePeriod <= 0 {
+		s.notify()
+	}
+	return req, nil
+}
+
+// CancelErasure 在宽限期内取消数据删除请求
+func (s *privacyService) CancelErasure(ctx context.Context, userID uint) (*models.PrivacyRequest, error) {
+	pending, err := s.repo.FindByUser(ctx, userID, models.PrivacyRequestErasure, models.PrivacyStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, req := range pending {
+		var cancelled bool
+		err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+			ok, err := s.repo.TransitionStatus(ctx, req.ID, models.PrivacyStatusPending, models.PrivacyStatusCancelled)
+			if err != nil || !ok {
+				return err
+			}
+
+			cancelled = true
+			req.Status = models.PrivacyStatusCancelled
+			return s.record(ctx, req, PrivacyStepErasureCancelled, "")
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if cancelled {
+			return req, nil
+		}
+	}
+
+	return nil, ErrPrivacyErasureNotFound
+}
+
+// ProcessDue 处理到期的导出和删除请求，并清理过期的导出文件
+func (s *privacyService) ProcessDue(ctx context.Context) {
+	now := time.Now()
+
+	// 认领后超时仍未完成的请求，说明处理实例已退出，放回待处理重新执行
+	requeued, err := s.repo.RequeueStale(ctx, now.Add(-s.config.ClaimTimeout))
+	if err != nil {
+		utils.Errorf("回收处理超时的隐私请求失败: %v", err)
+	} else if requeued > 0 {
+		utils.Warnf("已将 %d 个处理超时的隐私请求放回待处理", requeued)
+	}
+
+	exports, err := s.repo.ListDue(ctx, models.PrivacyRequestExport, now, privacyBatchSize)
+	if err != nil {
+		utils.Errorf("获取待处理数据导出请求失败: %v", err)
+	}
+	for _, req := range exports {
+		if s.claim(ctx, req) {
+			s.processExport(ctx, req)
+		}
+	}
+
+	erasures, err := s.repo.ListDue(ctx, models.PrivacyRequestErasure, now, privacyBatchSize)
+	if err != nil {
+		utils.Errorf("获取待处理数据删除请求失败: %v", err)
+	}
+	for _, req := range erasures {
+		if s.claim(ctx, req) {
+			s.processErasure(ctx, req)
+		}
+	}
+
+	expired, err := s.repo.ListExpiredExports(ctx, now, privacyBatchSize)
+	if err != nil {
+		utils.Errorf("获取过期数据导出请求失败: %v", err)
+	}
+	for _, req := range expired {
+		s.expireExport(ctx, req, "导出文件已过期")
+	}
+}
+
+// Run 定期处理隐私请求，直到上下文取消
+// 新的导出请求会立即唤醒处理，无需等待下一个周期
+func (s *privacyService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+
+		s.ProcessDue(ctx)
+	}
+}
+
+// notify 唤醒后台处理任务
+func (s *privacyService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// claim 认领待处理请求，多实例部署时只有一个实例能认领成功
+// 认领时间作为租约，超过 ClaimTimeout 仍未完成时由 ProcessDue 放回待处理
+func (s *privacyService) claim(ctx context.Context, req *models.PrivacyRequest) bool {
+	ok, err := s.repo.Claim(ctx, req.ID)
+	if err != nil {
+		utils.Errorf("认领隐私请求 %d 失败: %v", req.ID, err)
+		return false
+	}
+
+	now := time.Now()
+	req.Status = models.PrivacyStatusProcessing
+	req.ClaimedAt = &now
+	return ok
+}
+
+// processExport 生成数据导出文件
+func (s *privacyService) processExport(ctx context.Context, req *models.PrivacyRequest) {
+	s.recordQuietly(ctx, req, PrivacyStepExportStarted, "")
+
+	export, err := s.buildExport(ctx, req)
+	if err != nil {
+		utils.Errorf("生成数据导出文件失败, 请求ID: %d, 错误: %v", req.ID, err)
+		req.Status = models.PrivacyStatusFailed
+		req.Error = truncateError(err)
+		s.save(ctx, req)
+		s.recordQuietly(ctx, req, PrivacyStepExportFailed, req.Error)
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.config.ExportTTL)
+	req.Status = models.PrivacyStatusCompleted
+	req.CompletedAt = &now
+	req.ExpiresAt = &expiresAt
+	req.Error = ""
+
+	// 导出文件与请求状态一起提交，避免请求已完成但文件不存在
+	err = s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.SaveExport(ctx, export); err != nil {
+			return err
+		}
+		return s.repo.Update(ctx, req)
+	})
+	if err != nil {
+		// 保持处理中状态，认领超时后重新生成
+		utils.Errorf("保存数据导出文件失败, 请求ID: %d, 错误: %v", req.ID, err)
+		return
+	}
+	s.recordQuietly(ctx, req, PrivacyStepExportCompleted, export.FileName)
+}
+
+// buildExport 收集各模块数据并生成导出文件
+// 导出文件保存在数据库中，多实例部署时任一实例都能提供下载
+func (s *privacyService) buildExport(ctx context.Context, req *models.PrivacyRequest) (*models.PrivacyExport, error) {
+	var sections []privacy.Section
+	for _, exporter := range s.registry.Exporters() {
+		data, err := exporter.Export(ctx, req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("模块 %s 导出失败: %w", exporter.Name(), err)
+		}
+
+		sections = append(sections, privacy.Section{Module: exporter.Name(), Data: data})
+		s.recordQuietly(ctx, req, PrivacyStepModuleExported, exporter.Name())
+	}
+
+	manifest := privacy.Manifest{
+		RequestID:   req.ID,
+		UserID:      req.UserID,
+		GeneratedAt: time.Now(),
+	}
+	var buf bytes.Buffer
+	if err := privacy.WriteArchive(&buf, req.Format, manifest, sections); err != nil {
+		return nil, err
+	}
+
+	return &models.PrivacyExport{
+		RequestID: req.ID,
+		UserID:    req.UserID,
+		FileName:  fmt.Sprintf("user-%d-request-%d.%s", req.UserID, req.ID, req.Format),
+		Content:   buf.Bytes(),
+	}, nil
+}
+
+// processErasure 依次调用各模块处理器删除用户数据
+// 任一模块失败时整体在重试间隔后重新执行，因此各模块处理器必须是幂等的
+func (s *privacyService) processErasure(ctx context.Context, req *models.PrivacyRequest) {
+	s.recordQuietly(ctx, req, PrivacyStepErasureStarted, "")
+
+	for _, eraser := range s.registry.Erasers() {
+		err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+			return eraser.Erase(ctx, req.UserID)
+		})
+		if err != nil {
+			utils.Errorf("删除用户数据失败, 请求ID: %d, 模块: %s, 错误: %v", req.ID, eraser.Name(), err)
+			req.Status = models.PrivacyStatusPending
+			req.ClaimedAt = nil
+			req.ScheduledAt = time.Now().Add(s.config.RetryInterval)
+			req.Error = truncateError(fmt.Errorf("模块 %s: %w", eraser.Name(), err))
+			s.save(ctx, req)
+			s.recordQuietly(ctx, req, PrivacyStepErasureFailed, req.Error)
+			return
+		}
+
+		s.recordQuietly(ctx, req, PrivacyStepModuleErased, eraser.Name())
+	}
+
+	// 已生成的导出文件同样包含个人信息，一并删除
+	exports, err := s.repo.FindByUser(ctx, req.UserID, models.PrivacyRequestExport, models.PrivacyStatusCompleted)
+	if err != nil {
+		utils.Errorf("获取用户 %d 的数据导出请求失败: %v", req.UserID, err)
+	}
+	for _, export := range exports {
+		s.expireExport(ctx, export, "用户数据已删除")
+	}
+
+	now := time.Now()
+	req.Status = models.PrivacyStatusCompleted
+	req.CompletedAt = &now
+	req.Error = ""
+	s.save(ctx, req)
+	s.recordQuietly(ctx, req, PrivacyStepErasureCompleted, "")
+}
+
+// expireExport 删除导出文件并将请求标记为已过期
+func (s *privacyService) expireExport(ctx context.Context, req *models.PrivacyRequest, reason string) {
+	req.Status = models.PrivacyStatusExpired
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteExport(ctx, req.ID); err != nil {
+			return err
+		}
+		return s.repo.Update(ctx, req)
+	})
+	if err != nil {
+		utils.Errorf("删除数据导出文件失败, 请求ID: %d, 错误: %v", req.ID, err)
+		return
+	}
+	s.recordQuietly(ctx, req, PrivacyStepExportExpired, reason)
+}
+
+// save 保存请求状态，失败时只记录日志
+func (s *privacyService) save(ctx context.Context, req *models.PrivacyRequest) {
+	if err := s.repo.Update(ctx, req); err != nil {
+		utils.Errorf("保存隐私请求 %d 失败: %v", req.ID, err)
+	}
+}
+
+// record 写入合规记录
+func (s *privacyService) record(ctx context.Context, req *models.PrivacyRequest, step, detail string) error {
+	return s.repo.AddLog(ctx, &models.PrivacyRequestLog{
+		RequestID: req.ID,
+		UserID:    req.UserID,
+		Step:      step,
+		Detail:    utils.TruncateString(detail, privacyErrorLength),
+	})
+}
+
+// recordQuietly 写入合规记录，失败时只记录日志，用于后台任务
+func (s *privacyService) recordQuietly(ctx context.Context, req *models.PrivacyRequest, step, detail string) {
+	if err := s.record(ctx, req, step, detail); err != nil {
+		utils.Errorf("写入隐私请求记录失败, 请求ID: %d, 步骤: %s, 错误: %v", req.ID, step, err)
+	}
+}
+
+// truncateError 截断错误信息以适应字段长度，按字符截断避免产生无效的UTF-8
+func truncateError(err error) string {
+	return utils.TruncateString(err.Error(), privacyErrorLength)
+}
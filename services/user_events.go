@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/outbox"
+	"gitee.com/NextEraAbyss/gin-template/models"
+)
+
+// 用户领域事件类型
+const (
+	EventUserRegistered      = "user.registered"
+	EventUserUpdated         = "user.updated"
+	EventUserPasswordChanged = "user.password_changed"
+	EventUserDeleted         = "user.deleted"
+	EventUserErased          = "user.erased"
+)
+
+// userAggregateType 用户聚合类型
+const userAggregateType = "user"
+
+// UserEventPayload 用户注册和更新事件内容
+type UserEventPayload struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+	Status   int    `json:"status"`
+}
+
+// UserIDEventPayload 只包含用户ID的事件内容，用于修改密码、删除和数据擦除事件
+type UserIDEventPayload struct {
+	ID uint `json:"id"`
+}
+
+// newUserEventPayload 从用户模型创建事件内容，不包含密码等敏感信息
+func newUserEventPayload(user *models.User) UserEventPayload {
+	return UserEventPayload{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Nickname: user.Nickname,
+		Avatar:   user.Avatar,
+		Status:   user.Status,
+	}
+}
+
+// emitUserEvent 将用户事件写入发件箱，需在与状态变更相同的事务上下文中调用
+func (s *userService) emitUserEvent(ctx context.Context, eventType string, userID uint, payload interface{}) error {
+	event, err := outbox.NewEvent(ctx, eventType, userAggregateType, userID, payload)
+	if err != nil {
+		return err
+	}
+
+	return s.outbox.Add(ctx, event)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"gitee.com/NextEraAbyss/gin-template/models"
@@ -13,6 +14,14 @@ import (
 	"gorm.io/gorm"
 )
 
+// 用户服务错误，消息可直接返回给客户端
+var (
+	ErrUserNotFound     = errors.New("用户不存在")
+	ErrUsernameExists   = errors.New("用户名已存在")
+	ErrEmailExists      = errors.New("邮箱已存在")
+	ErrOldPasswordWrong = errors.New("旧密码错误")
+)
+
 // BaseService 基础服务接口，定义通用的服务方法
 type BaseService interface {
 	// ServiceName 获取服务名称
@@ -44,16 +53,30 @@ type UserService interface {
 	// GetByEmail 根据邮箱获取用户
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 
+	// IsAdmin 判断用户是否为管理员
+	IsAdmin(ctx context.Context, userID uint) (bool, error)
+
 	// ChangePassword 修改密码
 	ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) error
 
 	// ResetPassword 重置密码
 	ResetPassword(ctx context.Context, email string) error
+
+	// Anonymize 匿名化用户的个人信息，保留用户记录
+	Anonymize(ctx context.Context, id uint) error
+
+	// Import 从CSV或NDJSON流中批量导入用户，options.DryRun 为 true 时只校验不写入
+	Import(ctx context.Context, r io.Reader, format string, options *validation.UserImportQueryDTO) (*validation.UserImportResultDTO, error)
+
+	// Export 将符合条件的用户以CSV或NDJSON格式流式写出
+	Export(ctx context.Context, query *validation.UserExportQueryDTO, w io.Writer) error
 }
 
 // userService 用户服务实现
 type userService struct {
-	repo repositories.UserRepository
+	repo       repositories.UserRepository
+	outbox     repositories.OutboxRepository
+	transactor repositories.Transactor
 }
 
 // ServiceName 获取服务名称
@@ -62,8 +85,13 @@ func (s *userService) ServiceName() string {
 }
 
 // NewUserService 创建用户服务实例
-func NewUserService(repo repositories.UserRepository) UserService {
-	return &userService{repo: repo}
+// 用户状态变更与对应的领域事件在同一事务中写入发件箱
+func NewUserService(repo repositories.UserRepository, outbox repositories.OutboxRepository, transactor repositories.Transactor) UserService {
+	return &userService{
+		repo:       repo,
+		outbox:     outbox,
+		transactor: transactor,
+	}
 }
 
 // Create 创建用户
@@ -71,13 +99,13 @@ func (s *userService) Create(ctx context.Context, user *models.User) error {
 	// 检查用户名是否已存在
 	existingUser, err := s.repo.GetByUsername(ctx, user.Username)
 	if err == nil && existingUser != nil {
-		return errors.New("用户名已存在")
+		return ErrUsernameExists
 	}
 
 	// 检查邮箱是否已存在
 	existingUser, err = s.repo.GetByEmail(ctx, user.Email)
 	if err == nil && existingUser != nil {
-		return errors.New("邮箱已存在")
+		return ErrEmailExists
 	}
 
 	// 验证密码强度
@@ -101,7 +129,13 @@ func (s *userService) Create(ctx context.Context, user *models.User) error {
 	// 设置最后登录时间为当前时间
 	user.LastLoginAt = time.Now()
 
-	return s.repo.Create(ctx, user)
+	return s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, user); err != nil {
+			return err
+		}
+
+		return s.emitUserEvent(ctx, EventUserRegistered, user.ID, newUserEventPayload(user))
+	})
 }
 
 // GetByID 根据ID获取用户
@@ -114,15 +148,19 @@ func (s *userService) Update(ctx context.Context, user *models.User) error {
 	// 检查用户是否存在
 	existingUser, err := s.repo.GetByID(ctx, user.ID)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
 		return err
 	}
 
 	if existingUser == nil {
-		return fmt.Errorf("用户不存在")
+		return ErrUserNotFound
 	}
 
 	// 如果更新了密码，需要重新加密
-	if user.Password != "" && user.Password != existingUser.Password {
+	passwordChanged := user.Password != "" && user.Password != existingUser.Password
+	if passwordChanged {
 		hashedPassword, err := utils.HashPassword(user.Password)
 		if err != nil {
 			return err
@@ -132,7 +170,21 @@ func (s *userService) Update(ctx context.Context, user *models.User) error {
 	}
 
 	// 更新用户信息
-	return s.repo.Update(ctx, user)
+	return s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.emitUserEvent(ctx, EventUserUpdated, user.ID, newUserEventPayload(user)); err != nil {
+			return err
+		}
+
+		if passwordChanged {
+			return s.emitUserEvent(ctx, EventUserPasswordChanged, user.ID, UserIDEventPayload{ID: user.ID})
+		}
+
+		return nil
+	})
 }
 
 // Delete 删除用户
@@ -141,12 +193,58 @@ func (s *userService) Delete(ctx context.Context, id uint) error {
 	_, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("用户不存在")
+			return ErrUserNotFound
 		}
 		return err
 	}
 
-	return s.repo.Delete(ctx, id)
+	return s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		return s.emitUserEvent(ctx, EventUserDeleted, id, UserIDEventPayload{ID: id})
+	})
+}
+
+// Anonymize 匿名化用户的个人信息，保留用户记录
+// 用户名和邮箱替换为基于ID的占位值，密码替换为随机值，并记录删除时间
+func (s *userService) Anonymize(ctx context.Context, id uint) error {
+	return s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		user, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		randomPassword, err := utils.GenerateRandomPassword()
+		if err != nil {
+			return err
+		}
+
+		hashedPassword, err := utils.HashPassword(randomPassword)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		user.Username = fmt.Sprintf("erased_%d", user.ID)
+		user.Email = fmt.Sprintf("erased_%d@erased.invalid", user.ID)
+		user.Nickname = ""
+		user.Avatar = ""
+		user.Password = hashedPassword
+		if user.DeletedAt == nil {
+			user.DeletedAt = &now
+		}
+
+		if err := s.repo.Update(ctx, user); err != nil {
+			return err
+		}
+
+		return s.emitUserEvent(ctx, EventUserErased, user.ID, UserIDEventPayload{ID: user.ID})
+	})
 }
 
 // GetByUsername 根据用户名获取用户
@@ -159,6 +257,18 @@ func (s *userService) GetByEmail(ctx context.Context, email string) (*models.Use
 	return s.repo.GetByEmail(ctx, email)
 }
 
+// IsAdmin 判断用户是否为管理员，用户不存在时返回 false
+func (s *userService) IsAdmin(ctx context.Context, userID uint) (bool, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return user != nil && user.IsAdmin, nil
+}
+
 // List 获取用户列表
 func (s *userService) List(ctx context.Context, query *validation.UserQueryDTO) ([]models.User, int64, error) {
 
@@ -194,12 +304,15 @@ func (s *userService) ChangePassword(ctx context.Context, userID uint, oldPasswo
 	// 获取用户信息
 	user, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
 		return err
 	}
 
 	// 验证旧密码
 	if !utils.CheckPassword(oldPassword, user.Password) {
-		return errors.New("旧密码错误")
+		return ErrOldPasswordWrong
 	}
 
 	// 验证新密码强度
@@ -215,7 +328,7 @@ func (s *userService) ChangePassword(ctx context.Context, userID uint, oldPasswo
 
 	// 更新密码
 	user.Password = hashedPassword
-	return s.repo.Update(ctx, user)
+	return s.updatePassword(ctx, user)
 }
 
 // ResetPassword 重置密码
@@ -224,7 +337,7 @@ func (s *userService) ResetPassword(ctx context.Context, email string) error {
 	user, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("用户不存在")
+			return ErrUserNotFound
 		}
 		return err
 	}
@@ -242,10 +355,21 @@ func (s *userService) ResetPassword(ctx context.Context, email string) error {
 
 	// 更新密码
 	user.Password = hashedPassword
-	if err := s.repo.Update(ctx, user); err != nil {
+	if err := s.updatePassword(ctx, user); err != nil {
 		return err
 	}
 
 	// TODO: 发送邮件通知用户新密码
 	return nil
 }
+
+// updatePassword 保存新密码并发出修改密码事件
+func (s *userService) updatePassword(ctx context.Context, user *models.User) error {
+	return s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, user); err != nil {
+			return err
+		}
+
+		return s.emitUserEvent(ctx, EventUserPasswordChanged, user.ID, UserIDEventPayload{ID: user.ID})
+	})
+}
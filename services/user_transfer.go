@@ -0,0 +1,492 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/internal/outbox"
+	"gitee.com/NextEraAbyss/gin-template/models"
+	"gitee.com/NextEraAbyss/gin-template/repositories"
+	"gitee.com/NextEraAbyss/gin-template/utils"
+	"gitee.com/NextEraAbyss/gin-template/validation"
+)
+
+const (
+	// importBatchSize 导入时每批写入的记录数
+	importBatchSize = 500
+	// exportBatchSize 导出时每批读取的记录数
+	exportBatchSize = 500
+	// maxImportErrors 导入结果中最多返回的失败记录数
+	maxImportErrors = 1000
+	// maxNDJSONLineSize NDJSON单行最大长度
+	maxNDJSONLineSize = 1 << 20
+)
+
+// 导入导出错误
+var (
+	ErrUnsupportedFormat = errors.New("不支持的文件格式")
+	ErrInvalidImportFile = errors.New("导入文件无效") // 文件内容无法解析，如缺少表头或行过长
+)
+
+// userExportColumns 导出的CSV列，与 UserResponseDTO 的JSON字段一致
+var userExportColumns = []string{
+	"id", "username", "email", "nickname", "avatar", "status", "created_at", "updated_at", "last_login_at",
+}
+
+// rowError 单条记录的解析错误，不影响后续记录
+type rowError struct {
+	msg string
+}
+
+func (e *rowError) Error() string {
+	return e.msg
+}
+
+// userRowReader 用户导入记录读取器
+type userRowReader interface {
+	// Next 读取下一条记录，没有更多记录时返回 io.EOF
+	Next() (validation.UserCreateDTO, error)
+}
+
+// newUserRowReader 根据格式创建记录读取器
+func newUserRowReader(format string, r io.Reader) (userRowReader, error) {
+	switch format {
+	case validation.TransferFormatCSV:
+		return newCSVUserReader(r)
+	case validation.TransferFormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLineSize)
+		return &ndjsonUserReader{scanner: scanner}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// csvUserReader CSV格式读取器，首行为表头，列名与 UserCreateDTO 的JSON字段一致
+type csvUserReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+// newCSVUserReader 创建CSV读取器并解析表头
+func newCSVUserReader(r io.Reader) (*csvUserReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: CSV文件为空", ErrInvalidImportFile)
+		}
+		return nil, fmt.Errorf("%w: 读取CSV表头失败: %w", ErrInvalidImportFile, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	for _, required := range []string{"username", "password", "email"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: CSV表头缺少 %s 列", ErrInvalidImportFile, required)
+		}
+	}
+
+	return &csvUserReader{reader: reader, columns: columns}, nil
+}
+
+// Next 读取下一条记录
+func (r *csvUserReader) Next() (validation.UserCreateDTO, error) {
+	var dto validation.UserCreateDTO
+
+	record, err := r.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return dto, &rowError{msg: fmt.Sprintf("CSV格式错误: %v", parseErr.Err)}
+		}
+		return dto, err
+	}
+
+	field := func(name string) string {
+		if i, ok := r.columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	dto.Username = field("username")
+	dto.Password = field("password")
+	dto.Email = field("email")
+	dto.Nickname = field("nickname")
+
+	if status := field("status"); status != "" {
+		dto.Status, err = strconv.Atoi(status)
+		if err != nil {
+			return dto, &rowError{msg: "status 必须是整数"}
+		}
+	}
+
+	return dto, nil
+}
+
+// ndjsonUserReader NDJSON格式读取器，每行一个JSON对象，空行忽略
+type ndjsonUserReader struct {
+	scanner *bufio.Scanner
+}
+
+// Next 读取下一条记录
+func (r *ndjsonUserReader) Next() (validation.UserCreateDTO, error) {
+	var dto validation.UserCreateDTO
+
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(line), &dto); err != nil {
+			return dto, &rowError{msg: fmt.Sprintf("JSON格式错误: %v", err)}
+		}
+		return dto, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return dto, err
+	}
+	return dto, io.EOF
+}
+
+// importSeen 文件中已出现的用户名和邮箱及其所在行，用于检测文件内的重复记录
+type importSeen struct {
+	usernames map[string]int
+	emails    map[string]int
+}
+
+// importItem 待写入的导入记录
+type importItem struct {
+	row      int
+	user     *models.User // Password 为明文，hashed 为 true 后为哈希值
+	hashed   bool
+	existing *models.User // 用户名已存在时为已有用户
+}
+
+// Import 从CSV或NDJSON流中导入用户
+// 用户名不存在时创建用户；已存在时更新邮箱、昵称和状态，只有 UpdatePassword 为 true 时才更新密码
+// DryRun 时同样分批检测冲突，只是不写入数据库，结果与实际导入一致（写入失败除外）
+func (s *userService) Import(ctx context.Context, r io.Reader, format string, options *validation.UserImportQueryDTO) (*validation.UserImportResultDTO, error) {
+	rows, err := newUserRowReader(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &validation.UserImportResultDTO{
+		DryRun: options.DryRun,
+		Errors: make([]validation.UserImportErrorDTO, 0),
+	}
+
+	addError := func(row int, username, message string) {
+		result.Failed++
+		if len(result.Errors) >= maxImportErrors {
+			result.ErrorsTruncated = true
+			return
+		}
+		result.Errors = append(result.Errors, validation.UserImportErrorDTO{Row: row, Username: username, Message: message})
+	}
+
+	batch := make([]*importItem, 0, importBatchSize)
+	seen := &importSeen{usernames: make(map[string]int), emails: make(map[string]int)}
+
+	// flush 写入当前批次，整批写入失败时逐条重试，使错误对应到具体的记录
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, err := s.prepareImport(ctx, batch, seen, options, addError)
+		if err != nil {
+			return err
+		}
+
+		// DryRun 只检测冲突，不写入
+		if options.DryRun {
+			result.Succeeded += len(items)
+			batch = batch[:0]
+			return nil
+		}
+
+		if err := s.writeImport(ctx, items, options.UpdatePassword); err != nil {
+			utils.Errorf("批量导入用户失败，逐条重试: %v", err)
+			for _, item := range items {
+				single, err := s.prepareImport(ctx, []*importItem{item}, seen, options, addError)
+				if err != nil {
+					return err
+				}
+				if len(single) == 0 {
+					continue
+				}
+				if err := s.writeImport(ctx, single, options.UpdatePassword); err != nil {
+					utils.Errorf("导入用户 %s 失败: %v", item.user.Username, err)
+					addError(item.row, item.user.Username, "写入数据库失败")
+					continue
+				}
+				result.Succeeded++
+			}
+		} else {
+			result.Succeeded += len(items)
+		}
+
+		batch = batch[:0]
+		return nil
+	}
+
+	for row := 1; ; row++ {
+		dto, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var rowErr *rowError
+		if errors.As(err, &rowErr) {
+			result.Total++
+			addError(row, dto.Username, rowErr.Error())
+			continue
+		}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) || ctx.Err() != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: 读取文件失败: %w", ErrInvalidImportFile, err)
+		}
+
+		result.Total++
+
+		// 使用与创建用户接口相同的校验规则
+		if err := utils.ValidateStruct(&dto); err != nil {
+			addError(row, dto.Username, utils.TranslateValidationError(err))
+			continue
+		}
+
+		user := dto.ToModel()
+		if user.Status == 0 {
+			user.Status = 1
+		}
+
+		batch = append(batch, &importItem{row: row, user: &user})
+
+		if len(batch) >= importBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// prepareImport 查询批次中已存在的用户，并校验用户名和邮箱冲突
+// 冲突的记录通过 addError 记为失败，返回可以写入的记录；非 DryRun 时需要写入的密码在此处哈希
+func (s *userService) prepareImport(ctx context.Context, batch []*importItem, seen *importSeen,
+	options *validation.UserImportQueryDTO, addError func(row int, username, message string)) ([]*importItem, error) {
+	usernames := make([]string, 0, len(batch))
+	emails := make([]string, 0, len(batch))
+	for _, item := range batch {
+		usernames = append(usernames, item.user.Username)
+		emails = append(emails, item.user.Email)
+	}
+
+	existing, err := s.repo.FindByUsernamesOrEmails(ctx, usernames, emails)
+	if err != nil {
+		return nil, err
+	}
+	byUsername := make(map[string]*models.User, len(existing))
+	byEmail := make(map[string]*models.User, len(existing))
+	for _, user := range existing {
+		byUsername[user.Username] = user
+		byEmail[user.Email] = user
+	}
+
+	items := make([]*importItem, 0, len(batch))
+	for _, item := range batch {
+		username, email := item.user.Username, item.user.Email
+
+		// 整批写入失败后逐条重试时，记录会再次经过检测，与自身不算重复
+		if row, ok := seen.usernames[username]; ok && row != item.row {
+			addError(item.row, username, fmt.Sprintf("用户名与第%d条记录重复", row))
+			continue
+		}
+		if row, ok := seen.emails[email]; ok && row != item.row {
+			addError(item.row, username, fmt.Sprintf("邮箱与第%d条记录重复", row))
+			continue
+		}
+		if owner, ok := byEmail[email]; ok && owner.Username != username {
+			addError(item.row, username, "邮箱已被其他用户使用")
+			continue
+		}
+		seen.usernames[username] = item.row
+		seen.emails[email] = item.row
+
+		item.existing = byUsername[username]
+		if !options.DryRun && !item.hashed && (item.existing == nil || options.UpdatePassword) {
+			hashedPassword, err := utils.HashPassword(item.user.Password)
+			if err != nil {
+				return nil, err
+			}
+			item.user.Password = hashedPassword
+			item.hashed = true
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// writeImport 在一个事务中创建新用户并更新已有用户，同时写入对应的用户事件
+func (s *userService) writeImport(ctx context.Context, items []*importItem, updatePassword bool) error {
+	fields := []string{"email", "nickname", "status", "updated_at"}
+	if updatePassword {
+		fields = append(fields, "password")
+	}
+
+	return s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		created := make([]*models.User, 0, len(items))
+		for _, item := range items {
+			if item.existing != nil {
+				continue
+			}
+			user := *item.user
+			user.LastLoginAt = time.Now()
+			created = append(created, &user)
+		}
+		if err := s.repo.CreateInBatches(ctx, created, importBatchSize); err != nil {
+			return err
+		}
+
+		events := make([]*models.OutboxEvent, 0, len(items))
+		addEvent := func(eventType string, userID uint, payload interface{}) error {
+			event, err := outbox.NewEvent(ctx, eventType, userAggregateType, userID, payload)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+			return nil
+		}
+
+		for _, user := range created {
+			if err := addEvent(EventUserRegistered, user.ID, newUserEventPayload(user)); err != nil {
+				return err
+			}
+		}
+
+		for _, item := range items {
+			if item.existing == nil {
+				continue
+			}
+			user := *item.user
+			user.ID = item.existing.ID
+			if err := s.repo.UpdateFields(ctx, &user, fields...); err != nil {
+				return err
+			}
+
+			// 事件内容为更新后的完整用户信息
+			updated := *item.existing
+			updated.Email, updated.Nickname, updated.Status = user.Email, user.Nickname, user.Status
+			if err := addEvent(EventUserUpdated, updated.ID, newUserEventPayload(&updated)); err != nil {
+				return err
+			}
+			if updatePassword {
+				if err := addEvent(EventUserPasswordChanged, updated.ID, UserIDEventPayload{ID: updated.ID}); err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+		return s.outbox.Add(ctx, events...)
+	})
+}
+
+// Export 将符合条件的用户以CSV或NDJSON格式流式写出
+// 数据分批读取，每批写完后若 w 支持 Flush 则立即刷新
+func (s *userService) Export(ctx context.Context, query *validation.UserExportQueryDTO, w io.Writer) error {
+	repoQuery := &repositories.UserQueryParams{
+		Keyword: query.Keyword,
+		Status:  query.Status,
+	}
+
+	flusher, _ := w.(interface{ Flush() })
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	switch query.Format {
+	case validation.TransferFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(userExportColumns); err != nil {
+			return err
+		}
+
+		err := s.repo.FindInBatches(ctx, repoQuery, exportBatchSize, func(users []*models.User) error {
+			for _, user := range users {
+				dto := validation.FromUser(*user)
+				record := []string{
+					strconv.FormatUint(uint64(dto.ID), 10), dto.Username, dto.Email, dto.Nickname, dto.Avatar,
+					strconv.Itoa(dto.Status), dto.CreatedAt, dto.UpdatedAt, dto.LastLoginAt,
+				}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+
+			writer.Flush()
+			flush()
+			return writer.Error()
+		})
+
+		// 没有数据时也要写出表头
+		writer.Flush()
+		flush()
+		if err != nil {
+			return err
+		}
+		return writer.Error()
+	case validation.TransferFormatNDJSON:
+		encoder := json.NewEncoder(w)
+
+		return s.repo.FindInBatches(ctx, repoQuery, exportBatchSize, func(users []*models.User) error {
+			for _, user := range users {
+				if err := encoder.Encode(validation.FromUser(*user)); err != nil {
+					return err
+				}
+			}
+
+			flush()
+			return nil
+		})
+	default:
+		return ErrUnsupportedFormat
+	}
+}
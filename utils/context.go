@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"context"
+)
+
+// contextKey 上下文键类型，避免与其他包的键冲突
+type contextKey string
+
+const (
+	requestIDContextKey contextKey = "request_id"
+	userIDContextKey    contextKey = "user_id"
+)
+
+// WithRequestID 将请求ID写入上下文
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// RequestIDFromContext 从上下文中获取请求ID
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
+
+// WithUserID 将当前登录用户ID写入上下文
+func WithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userIDContextKey, userID)
+}
+
+// UserIDFromContext 从上下文中获取当前登录用户ID
+func UserIDFromContext(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	userID, ok := ctx.Value(userIDContextKey).(uint)
+	return userID, ok
+}
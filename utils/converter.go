@@ -23,3 +23,16 @@ func ParseFloat64(s string) (float64, error) {
 func ParseBool(s string) (bool, error) {
 	return strconv.ParseBool(s)
 }
+
+// TruncateString 将字符串截断为最多 maxRunes 个字符，按字符而非字节截断，不会截断多字节字符.
+// 数据库 varchar(n) 的长度按字符计算，写入前可使用该函数截断.
+func TruncateString(s string, maxRunes int) string {
+	count := 0
+	for i := range s {
+		if count == maxRunes {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
@@ -0,0 +1,322 @@
+package utils
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotateTimeFormat 历史日志文件名中的时间格式
+const rotateTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotateConfig 日志文件切分配置
+type RotateConfig struct {
+	Filename   string // 日志文件路径，如 logs/app.log
+	MaxSizeMB  int    // 单个文件的最大大小（MB），超过后切分，0 表示不按大小切分
+	Daily      bool   // 是否在每天零点切分
+	MaxBackups int    // 保留的历史文件数量，0 表示不限制
+	MaxAgeDays int    // 历史文件保留天数，0 表示不限制
+	Compress   bool   // 是否使用gzip压缩历史文件
+}
+
+// RotatingFile 按大小和日期切分的日志文件
+// 切分后的历史文件命名为 app-2006-01-02T15-04-05.000.log，在后台压缩并按数量和保留天数清理
+type RotatingFile struct {
+	config RotateConfig
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	day    string // 当前文件的日期
+	closed bool   // 是否已关闭，关闭后写入的日志被丢弃
+
+	millCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewRotatingFile 创建按大小和日期切分的日志文件
+func NewRotatingFile(config RotateConfig) (*RotatingFile, error) {
+	if config.Filename == "" {
+		return nil, fmt.Errorf("日志文件路径不能为空")
+	}
+	config.Filename = cleanPath(config.Filename)
+
+	f := &RotatingFile{
+		config: config,
+		millCh: make(chan struct{}, 1),
+	}
+
+	f.mu.Lock()
+	err := f.open()
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	f.wg.Add(1)
+	go f.millRun()
+	// 清理启动前遗留的历史文件
+	f.millCh <- struct{}{}
+
+	return f, nil
+}
+
+// Write 写入日志，需要时先切分文件
+// 关闭后的写入直接丢弃，不会重新打开文件
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return len(p), nil
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Sync 将文件内容刷入磁盘
+func (f *RotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close 关闭文件并等待后台的压缩和清理完成，重复调用时直接返回
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	close(f.millCh)
+	f.wg.Wait()
+	return err
+}
+
+// shouldRotate 判断写入前是否需要切分
+func (f *RotatingFile) shouldRotate(n int64) bool {
+	if f.config.Daily && time.Now().Format("2006-01-02") != f.day {
+		return true
+	}
+	maxSize := int64(f.config.MaxSizeMB) << 20
+	return maxSize > 0 && f.size > 0 && f.size+n > maxSize
+}
+
+// open 打开日志文件，已存在时追加写入
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.config.Filename), 0o750); err != nil {
+		return fmt.Errorf("创建日志目录失败: %w", err)
+	}
+
+	// #nosec G304 -- 路径已经过清理
+	file, err := os.OpenFile(f.config.Filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("读取日志文件信息失败: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	// 沿用已有文件的日期，重启后跨天的文件在首次写入时切分
+	f.day = time.Now().Format("2006-01-02")
+	if f.size > 0 {
+		f.day = info.ModTime().Format("2006-01-02")
+	}
+	return nil
+}
+
+// rotate 将当前文件重命名为历史文件并创建新文件
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	if err := os.Rename(f.config.Filename, f.backupName(time.Now())); err != nil {
+		return fmt.Errorf("重命名日志文件失败: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	select {
+	case f.millCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// backupName 历史文件名
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := f.nameParts()
+	return filepath.Join(dir, prefix+t.Format(rotateTimeFormat)+ext)
+}
+
+// nameParts 拆分日志文件名，返回目录、历史文件名前缀和扩展名
+func (f *RotatingFile) nameParts() (string, string, string) {
+	dir := filepath.Dir(f.config.Filename)
+	base := filepath.Base(f.config.Filename)
+	ext := filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// millRun 在后台执行压缩和清理
+func (f *RotatingFile) millRun() {
+	defer f.wg.Done()
+	for range f.millCh {
+		f.mill()
+	}
+}
+
+// backupFile 历史日志文件
+type backupFile struct {
+	path      string
+	timestamp time.Time
+}
+
+// mill 压缩历史文件，并删除超出数量或保留天数的历史文件
+func (f *RotatingFile) mill() {
+	backups, err := f.backups()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取历史日志文件失败: %v\n", err)
+		return
+	}
+
+	var remove []backupFile
+	if f.config.MaxBackups > 0 && len(backups) > f.config.MaxBackups {
+		remove = append(remove, backups[f.config.MaxBackups:]...)
+		backups = backups[:f.config.MaxBackups]
+	}
+	if f.config.MaxAgeDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -f.config.MaxAgeDays)
+		kept := backups[:0]
+		for _, backup := range backups {
+			if backup.timestamp.Before(cutoff) {
+				remove = append(remove, backup)
+				continue
+			}
+			kept = append(kept, backup)
+		}
+		backups = kept
+	}
+
+	for _, backup := range remove {
+		if err := os.Remove(backup.path); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "删除历史日志文件失败: %v\n", err)
+		}
+	}
+
+	if !f.config.Compress {
+		return
+	}
+	for _, backup := range backups {
+		if strings.HasSuffix(backup.path, ".gz") {
+			continue
+		}
+		if err := compressFile(backup.path); err != nil {
+			fmt.Fprintf(os.Stderr, "压缩历史日志文件失败: %v\n", err)
+		}
+	}
+}
+
+// backups 列出历史文件，按切分时间从新到旧排序
+func (f *RotatingFile) backups() ([]backupFile, error) {
+	dir, prefix, ext := f.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		stamp := strings.TrimPrefix(name, prefix)
+		stamp = strings.TrimSuffix(strings.TrimSuffix(stamp, ".gz"), ext)
+		timestamp, err := time.ParseInLocation(rotateTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		if !isPathSafe(path, dir) {
+			continue
+		}
+		backups = append(backups, backupFile{path: path, timestamp: timestamp})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].timestamp.After(backups[j].timestamp)
+	})
+	return backups, nil
+}
+
+// compressFile 使用gzip压缩文件，完成后删除原文件
+func compressFile(path string) error {
+	// #nosec G304 -- 路径来自日志目录中的历史文件
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// #nosec G304 -- 路径来自日志目录中的历史文件
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,327 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/config"
+)
+
+// 日志输出目标类型
+const (
+	LogSinkConsole   = "console"   // 控制台，错误及以上级别写入标准错误，其余写入标准输出
+	LogSinkStdout    = "stdout"    // 标准输出
+	LogSinkStderr    = "stderr"    // 标准错误
+	LogSinkFile      = "file"      // 按大小和日期切分的日志文件
+	LogSinkSyslog    = "syslog"    // 系统日志
+	LogSinkCollector = "collector" // 本地TCP/UDP日志采集器
+)
+
+// 默认的异步写入缓冲条数和刷新等待时间
+const (
+	defaultLogBufferSize = 8192
+	logSyncTimeout       = 5 * time.Second
+)
+
+// droppedLogs 缓冲已满时丢弃的日志条数
+var droppedLogs atomic.Uint64
+
+// DroppedLogs 获取异步写入缓冲已满时丢弃的日志条数
+func DroppedLogs() uint64 {
+	return droppedLogs.Load()
+}
+
+// LogSink 日志输出目标
+// 只输出 MinLevel 到 MaxLevel 之间的日志，可按级别将日志路由到不同的目标
+type LogSink struct {
+	Name     string    // 名称，用于错误信息
+	Writer   io.Writer // 输出目标
+	MinLevel int       // 最低级别
+	MaxLevel int       // 最高级别
+}
+
+// NewLogConfig 根据配置创建日志配置
+// 部分输出目标创建失败时跳过该目标，返回的错误中包含失败原因
+func NewLogConfig(cfg *config.Config) (LogConfig, error) {
+	level, err := ParseLogLevel(cfg.Log.Level)
+	if err != nil {
+		level = INFO
+	}
+
+	logConfig := LogConfig{
+		Level:      level,
+		Format:     cfg.Log.Format,
+		ShowCaller: true,
+		Async:      cfg.Log.BufferSize > 0,
+		BufferSize: cfg.Log.BufferSize,
+	}
+
+	var errs []error
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// sinkLevel 解析输出目标的最低级别，无效时不额外过滤
+	sinkLevel := func(name string) int {
+		level, err := ParseLogLevel(name)
+		if err != nil {
+			errs = append(errs, err)
+			return DEBUG
+		}
+		return level
+	}
+
+	for _, sinkType := range cfg.Log.Sinks {
+		switch sinkType {
+		case LogSinkConsole:
+			minLevel := sinkLevel(cfg.Log.ConsoleLevel)
+			logConfig.Sinks = append(logConfig.Sinks,
+				LogSink{Name: LogSinkStdout, Writer: os.Stdout, MinLevel: minLevel, MaxLevel: WARN},
+				LogSink{Name: LogSinkStderr, Writer: os.Stderr, MinLevel: max(minLevel, ERROR), MaxLevel: FATAL},
+			)
+		case LogSinkFile:
+			rotate := RotateConfig{
+				Filename:   cfg.Log.FilePath,
+				MaxSizeMB:  cfg.Log.FileMaxSizeMB,
+				Daily:      cfg.Log.FileDaily,
+				MaxBackups: cfg.Log.FileMaxBackups,
+				MaxAgeDays: cfg.Log.FileMaxAgeDays,
+				Compress:   cfg.Log.FileCompress,
+			}
+			minLevel := sinkLevel(cfg.Log.FileLevel)
+			file, err := NewRotatingFile(rotate)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", LogSinkFile, err))
+				continue
+			}
+			logConfig.Sinks = append(logConfig.Sinks, LogSink{Name: cfg.Log.FilePath, Writer: file, MinLevel: minLevel, MaxLevel: FATAL})
+
+			if cfg.Log.ErrorFilePath == "" {
+				continue
+			}
+			rotate.Filename = cfg.Log.ErrorFilePath
+			errorFile, err := NewRotatingFile(rotate)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", LogSinkFile, err))
+				continue
+			}
+			logConfig.Sinks = append(logConfig.Sinks, LogSink{Name: cfg.Log.ErrorFilePath, Writer: errorFile, MinLevel: max(minLevel, ERROR), MaxLevel: FATAL})
+		case LogSinkSyslog:
+			minLevel := sinkLevel(cfg.Log.SyslogLevel)
+			writer, err := NewSyslogWriter(cfg.Log.SyslogNetwork, cfg.Log.SyslogAddr, cfg.Log.SyslogTag)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", LogSinkSyslog, err))
+				continue
+			}
+			logConfig.Sinks = append(logConfig.Sinks, LogSink{Name: LogSinkSyslog, Writer: writer, MinLevel: minLevel, MaxLevel: FATAL})
+		case LogSinkCollector:
+			minLevel := sinkLevel(cfg.Log.CollectorLevel)
+			writer, err := NewNetWriter(cfg.Log.CollectorNetwork, cfg.Log.CollectorAddr)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", LogSinkCollector, err))
+				continue
+			}
+			logConfig.Sinks = append(logConfig.Sinks, LogSink{Name: LogSinkCollector, Writer: writer, MinLevel: minLevel, MaxLevel: FATAL})
+		default:
+			errs = append(errs, fmt.Errorf("未知的日志输出目标: %s", sinkType))
+		}
+	}
+
+	return logConfig, errors.Join(errs...)
+}
+
+// closeSinks 关闭输出目标，标准输出和标准错误不关闭
+func closeSinks(sinks []LogSink) {
+	for _, sink := range sinks {
+		closer, ok := sink.Writer.(io.Closer)
+		if !ok || sink.Writer == os.Stdout || sink.Writer == os.Stderr {
+			continue
+		}
+		if err := closer.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "关闭日志输出 %s 失败: %v\n", sink.Name, err)
+		}
+	}
+}
+
+// asyncWriter 异步写入的日志输出
+// 日志先写入缓冲队列，由后台协程写入目标；队列已满时丢弃日志并计数，不阻塞业务
+type asyncWriter struct {
+	writer io.Writer
+	queue  chan asyncEntry
+	done   chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// asyncEntry 队列中的日志，flushed 不为空时表示刷新请求
+type asyncEntry struct {
+	data    []byte
+	flushed chan struct{}
+}
+
+// newAsyncWriter 创建异步写入的日志输出，size 为缓冲的日志条数
+func newAsyncWriter(writer io.Writer, size int) *asyncWriter {
+	if size <= 0 {
+		size = defaultLogBufferSize
+	}
+
+	w := &asyncWriter{
+		writer: writer,
+		queue:  make(chan asyncEntry, size),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write 将日志放入缓冲队列
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		droppedLogs.Add(1)
+		return len(p), nil
+	}
+
+	// zap 会复用写入的缓冲区，需要复制
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	select {
+	case w.queue <- asyncEntry{data: data}:
+	default:
+		droppedLogs.Add(1)
+	}
+	return len(p), nil
+}
+
+// Sync 等待已缓冲的日志写入目标
+func (w *asyncWriter) Sync() error {
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return nil
+	}
+
+	flushed := make(chan struct{})
+	timer := time.NewTimer(logSyncTimeout)
+	defer timer.Stop()
+
+	select {
+	case w.queue <- asyncEntry{flushed: flushed}:
+	case <-timer.C:
+		w.mu.RUnlock()
+		return errors.New("刷新日志超时")
+	}
+	w.mu.RUnlock()
+
+	select {
+	case <-flushed:
+	case <-timer.C:
+		return errors.New("刷新日志超时")
+	}
+
+	if syncer, ok := w.writer.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Close 写完已缓冲的日志后关闭目标
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.done
+	if closer, ok := w.writer.(io.Closer); ok && w.writer != os.Stdout && w.writer != os.Stderr {
+		return closer.Close()
+	}
+	return nil
+}
+
+// run 将队列中的日志写入目标
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	for entry := range w.queue {
+		if entry.flushed != nil {
+			close(entry.flushed)
+			continue
+		}
+		// 写入失败的日志同样计入丢弃数
+		if _, err := w.writer.Write(entry.data); err != nil {
+			droppedLogs.Add(1)
+		}
+	}
+}
+
+// NetWriter 写入本地TCP/UDP日志采集器的日志输出
+// 每条日志为一行，连接断开后在下次写入时重新连接
+type NetWriter struct {
+	network string
+	address string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewNetWriter 创建写入TCP/UDP日志采集器的日志输出，network 为 tcp 或 udp
+func NewNetWriter(network, address string) (*NetWriter, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+	default:
+		return nil, fmt.Errorf("不支持的日志采集器协议: %s", network)
+	}
+	if address == "" {
+		return nil, errors.New("日志采集器地址不能为空")
+	}
+	return &NetWriter{network: network, address: address}, nil
+}
+
+// Write 写入一条日志，失败时断开连接
+func (w *NetWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.network, w.address, time.Second)
+		if err != nil {
+			return 0, err
+		}
+		w.conn = conn
+	}
+
+	_ = w.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	n, err := w.conn.Write(p)
+	if err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return n, err
+}
+
+// Close 关闭连接
+func (w *NetWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,14 @@
+//go:build !windows && !plan9
+
+package utils
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// NewSyslogWriter 创建写入系统日志的日志输出
+// network 和 address 为空时写入本机的系统日志服务，tag 为日志标识
+func NewSyslogWriter(network, address, tag string) (io.WriteCloser, error) {
+	return syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_LOCAL0, tag)
+}
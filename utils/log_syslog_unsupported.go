@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package utils
+
+import (
+	"errors"
+	"io"
+)
+
+// NewSyslogWriter 当前系统不支持系统日志
+func NewSyslogWriter(network, address, tag string) (io.WriteCloser, error) {
+	return nil, errors.New("当前系统不支持syslog")
+}
@@ -1,30 +1,21 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
-	"time"
+	"sync"
+	"sync/atomic"
 
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-// Logger 日志记录器
-type Logger struct {
-	config *LogConfig
-}
-
-// LogConfig 日志配置
-type LogConfig struct {
-	LogDir     string
-	LogLevel   zapcore.Level
-	ShowCaller bool
-}
-
 // 日志级别
 const (
 	DEBUG int = iota
@@ -34,187 +25,303 @@ const (
 	FATAL
 )
 
+// 日志输出格式
+const (
+	LogFormatJSON    = "json"    // JSON格式，便于日志系统采集
+	LogFormatConsole = "console" // 控制台格式，便于本地阅读
+)
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Level      int       // 日志级别
+	Format     string    // 输出格式：json 或 console
+	ShowCaller bool      // 是否输出调用位置
+	Stdout     io.Writer // 未配置 Sinks 时普通日志的输出，默认为标准输出
+	Stderr     io.Writer // 未配置 Sinks 时错误及以上级别日志的输出，默认为标准错误
+	Sinks      []LogSink // 日志输出目标，可按级别路由到多个目标
+	Async      bool      // 是否异步写入，缓冲已满时丢弃日志
+	BufferSize int       // 异步写入时每个输出目标缓冲的日志条数
+}
+
+// Logger 结构化日志记录器
+// 基于zap，Debug/Info/Warn/Error 以键值对附加字段，Debugf 等方法按格式输出
+type Logger struct {
+	sugar *zap.SugaredLogger
+}
+
+// logLevelNames 日志级别名称
+var logLevelNames = []string{"debug", "info", "warn", "error", "fatal"}
+
+// zapLevels 日志级别对应的zap级别
+var zapLevels = []zapcore.Level{zapcore.DebugLevel, zapcore.InfoLevel, zapcore.WarnLevel, zapcore.ErrorLevel, zapcore.FatalLevel}
+
 var (
-	// 默认日志级别
-	logLevel = INFO
-	// 日志前缀
-	logPrefix = []string{
-		"[DEBUG] ",
-		"[INFO] ",
-		"[WARN] ",
-		"[ERROR] ",
-		"[FATAL] ",
-	}
-	// 是否显示调用信息
-	showCaller = true
-	// 日志记录器
-	infoLogger  *log.Logger
-	errorLogger *log.Logger
+	// 当前日志级别，运行时可通过 SetLogLevel 调整
+	logLevel = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	// 当前日志配置
+	logConfig = LogConfig{Level: INFO, Format: LogFormatConsole, ShowCaller: true}
+	// 全局日志记录器
+	defaultLogger atomic.Pointer[Logger]
+	// 包级日志函数使用的记录器，调用位置跳过包级函数本身
+	packageLogger atomic.Pointer[Logger]
+	// 当前使用的日志输出目标
+	activeSinks   []LogSink
+	activeSinksMu sync.Mutex
 )
 
 func init() {
-	// 初始化日志记录器
-	infoLogger = log.New(os.Stdout, "[INFO] ", log.Ldate|log.Ltime|log.Lshortfile)
-	errorLogger = log.New(os.Stderr, "[ERROR] ", log.Ldate|log.Ltime|log.Lshortfile)
+	InitLogger(logConfig)
 }
 
 // InitLogger 初始化日志系统
-func InitLogger(level int, stdout, stderr io.Writer, showCallerInfo bool) {
-	logLevel = level
-
-	if stdout == nil {
-		stdout = os.Stdout
+func InitLogger(config LogConfig) {
+	if config.Stdout == nil {
+		config.Stdout = os.Stdout
+	}
+	if config.Stderr == nil {
+		config.Stderr = os.Stderr
+	}
+	if config.Format == "" {
+		config.Format = LogFormatConsole
+	}
+	logConfig = config
+	SetLogLevel(config.Level)
+
+	var encoder zapcore.Encoder
+	if config.Format == LogFormatJSON {
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.TimeKey = "time"
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoderConfig.EncodeDuration = zapcore.MillisDurationEncoder
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	} else {
+		encoderConfig := zap.NewDevelopmentEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05.000")
+		encoderConfig.EncodeDuration = zapcore.StringDurationEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
 	}
-	infoLogger = log.New(stdout, "", log.LstdFlags)
 
-	if stderr == nil {
-		stderr = os.Stderr
+	// 未配置输出目标时，错误及以上级别写入 Stderr，其余写入 Stdout
+	sinks := append([]LogSink(nil), config.Sinks...)
+	if len(sinks) == 0 {
+		sinks = []LogSink{
+			{Name: LogSinkStdout, Writer: config.Stdout, MinLevel: DEBUG, MaxLevel: WARN},
+			{Name: LogSinkStderr, Writer: config.Stderr, MinLevel: ERROR, MaxLevel: FATAL},
+		}
 	}
-	errorLogger = log.New(stderr, "", log.LstdFlags)
 
-	showCaller = showCallerInfo
+	cores := make([]zapcore.Core, 0, len(sinks))
+	for i, sink := range sinks {
+		var syncer zapcore.WriteSyncer
+		if config.Async {
+			writer := newAsyncWriter(sink.Writer, config.BufferSize)
+			sinks[i].Writer = writer
+			syncer = zapcore.AddSync(writer)
+		} else {
+			syncer = zapcore.Lock(zapcore.AddSync(sink.Writer))
+		}
+		cores = append(cores, zapcore.NewCore(encoder, syncer, sinkLevelEnabler(sink)))
+	}
+	core := zapcore.NewTee(cores...)
+
+	// 调用位置跳过 Logger 的方法，包级函数再多跳过一层
+	base := zap.New(core, zap.WithCaller(config.ShowCaller), zap.AddCallerSkip(1))
+	defaultLogger.Store(&Logger{sugar: base.Sugar()})
+	packageLogger.Store(&Logger{sugar: base.WithOptions(zap.AddCallerSkip(1)).Sugar()})
+
+	// 替换后关闭之前的输出目标
+	activeSinksMu.Lock()
+	previous := activeSinks
+	activeSinks = sinks
+	activeSinksMu.Unlock()
+	closeSinks(previous)
 }
 
-// getCallerInfo 获取调用者信息
-func getCallerInfo() string {
-	_, filename, line, ok := runtime.Caller(2)
-	if !ok {
-		return ""
+// sinkLevelEnabler 输出目标的级别过滤，同时受全局日志级别控制
+func sinkLevelEnabler(sink LogSink) zap.LevelEnablerFunc {
+	minLevel := zapLevels[max(sink.MinLevel, DEBUG)]
+	maxLevel := zapcore.FatalLevel
+	if sink.MaxLevel >= sink.MinLevel && sink.MaxLevel < FATAL {
+		maxLevel = zapLevels[sink.MaxLevel]
 	}
 
-	return fmt.Sprintf(" (%s:%d)", filename, line)
+	return func(level zapcore.Level) bool {
+		return logLevel.Enabled(level) && level >= minLevel && level <= maxLevel
+	}
 }
 
-// Debugf 打印调试日志
-func Debugf(format string, v ...interface{}) {
-	if logLevel <= DEBUG {
-		callerInfo := getCallerInfo()
-		infoLogger.Printf(logPrefix[DEBUG]+format+callerInfo, v...)
+// SyncLogger 将缓冲的日志写入输出目标
+func SyncLogger() error {
+	activeSinksMu.Lock()
+	defer activeSinksMu.Unlock()
+
+	var errs []error
+	for _, sink := range activeSinks {
+		if syncer, ok := sink.Writer.(interface{ Sync() error }); ok && sink.Writer != os.Stdout && sink.Writer != os.Stderr {
+			if err := syncer.Sync(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", sink.Name, err))
+			}
+		}
 	}
+	return errors.Join(errs...)
 }
 
-// Infof 打印信息日志
-func Infof(format string, v ...interface{}) {
-	if logLevel <= INFO {
-		callerInfo := getCallerInfo()
-		infoLogger.Printf(logPrefix[INFO]+format+callerInfo, v...)
-	}
+// CloseLogger 写完缓冲的日志并关闭输出目标，之后的日志输出到标准输出和标准错误
+func CloseLogger() {
+	config := logConfig
+	config.Stdout, config.Stderr, config.Sinks, config.Async = nil, nil, nil, false
+	InitLogger(config)
 }
 
-// Warnf 打印警告日志
-func Warnf(format string, v ...interface{}) {
-	if logLevel <= WARN {
-		callerInfo := getCallerInfo()
-		infoLogger.Printf(logPrefix[WARN]+format+callerInfo, v...)
-	}
+// L 获取全局日志记录器
+func L() *Logger {
+	return defaultLogger.Load()
 }
 
-// Errorf 打印错误日志
-func Errorf(format string, v ...interface{}) {
-	if logLevel <= ERROR {
-		callerInfo := getCallerInfo()
-		errorLogger.Printf(logPrefix[ERROR]+format+callerInfo, v...)
+// FromContext 获取附加了请求ID、用户ID和链路追踪ID的日志记录器
+func FromContext(ctx context.Context) *Logger {
+	logger := L()
+	if ctx == nil {
+		return logger
 	}
-}
 
-// Fatalf 打印致命错误日志并退出
-func Fatalf(format string, v ...interface{}) {
-	if logLevel <= FATAL {
-		callerInfo := getCallerInfo()
-		errorLogger.Fatalf(logPrefix[FATAL]+format+callerInfo, v...)
+	var fields []interface{}
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields = append(fields, "request_id", requestID)
+	}
+	if userID, ok := UserIDFromContext(ctx); ok {
+		fields = append(fields, "user_id", userID)
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		fields = append(fields, "trace_id", spanContext.TraceID().String())
 	}
-}
 
-// InitLogFile 初始化日志文件
-func InitLogFile(logDir string) {
-	// 确保日志目录存在
-	if err := os.MkdirAll(logDir, 0o750); err != nil {
-		log.Fatalf("Failed to create log directory: %v", err)
+	if len(fields) == 0 {
+		return logger
 	}
+	return logger.With(fields...)
+}
 
-	// 创建日志文件
-	currentTime := time.Now().Format("2006-01-02")
-	logFileName := filepath.Join(logDir, fmt.Sprintf("app_%s.log", currentTime))
-	logFileName = cleanPath(logFileName)
+// With 创建附加了键值对字段的日志记录器
+func (l *Logger) With(fields ...interface{}) *Logger {
+	return &Logger{sugar: l.sugar.With(fields...)}
+}
 
-	if !isPathSafe(logFileName, logDir) {
-		log.Fatalf("Invalid log file path")
-	}
+// Debug 打印调试日志，fields 为键值对
+func (l *Logger) Debug(msg string, fields ...interface{}) {
+	l.sugar.Debugw(msg, fields...)
+}
 
-	// #nosec G304 -- 路径已经过清理和验证
-	logFile, err := os.OpenFile(logFileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
-	if err != nil {
-		log.Fatalf("Failed to open log file: %v", err)
-	}
+// Info 打印信息日志，fields 为键值对
+func (l *Logger) Info(msg string, fields ...interface{}) {
+	l.sugar.Infow(msg, fields...)
+}
 
-	// 创建错误日志文件
-	errLogFileName := filepath.Join(logDir, fmt.Sprintf("error_%s.log", currentTime))
-	errLogFileName = cleanPath(errLogFileName)
+// Warn 打印警告日志，fields 为键值对
+func (l *Logger) Warn(msg string, fields ...interface{}) {
+	l.sugar.Warnw(msg, fields...)
+}
 
-	if !isPathSafe(errLogFileName, logDir) {
-		log.Fatalf("Invalid error log file path")
-	}
+// Error 打印错误日志，fields 为键值对
+func (l *Logger) Error(msg string, fields ...interface{}) {
+	l.sugar.Errorw(msg, fields...)
+}
 
-	// #nosec G304 -- 路径已经过清理和验证
-	errLogFile, err := os.OpenFile(errLogFileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
-	if err != nil {
-		log.Fatalf("Failed to open error log file: %v", err)
-	}
+// Debugf 按格式打印调试日志
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	l.sugar.Debugf(format, v...)
+}
 
-	// 设置日志输出到文件和标准输出
-	multiOut := io.MultiWriter(logFile, os.Stdout)
-	multiErrOut := io.MultiWriter(errLogFile, os.Stderr)
+// Infof 按格式打印信息日志
+func (l *Logger) Infof(format string, v ...interface{}) {
+	l.sugar.Infof(format, v...)
+}
 
-	// 初始化日志系统
-	InitLogger(logLevel, multiOut, multiErrOut, showCaller)
+// Warnf 按格式打印警告日志
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	l.sugar.Warnf(format, v...)
 }
 
-// LogInfo 记录信息日志
-func LogInfo(msg string, fields ...interface{}) {
-	if len(fields)%2 != 0 {
-		errorLogger.Printf("Invalid number of fields for LogInfo: %d", len(fields))
+// Errorf 按格式打印错误日志
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	l.sugar.Errorf(format, v...)
+}
+
+// Fatalf 按格式打印致命错误日志并退出
+func (l *Logger) Fatalf(format string, v ...interface{}) {
+	l.sugar.Fatalf(format, v...)
+}
+
+// SetLogLevel 设置日志级别，可在运行时调用
+func SetLogLevel(level int) {
+	if level < DEBUG || level > FATAL {
 		return
 	}
+	logLevel.SetLevel(zapLevels[level])
+}
 
-	// 构建日志消息
-	logMsg := fmt.Sprintf("[%s] %s", time.Now().Format("2006-01-02 15:04:05"), msg)
-	fieldCount := len(fields)
-
-	for i := 0; i < fieldCount; i += 2 {
-		key, ok := fields[i].(string)
-		if !ok {
-			errorLogger.Printf("Invalid key type in LogInfo: %T", fields[i])
-			continue
+// GetLogLevel 获取当前日志级别
+func GetLogLevel() int {
+	current := logLevel.Level()
+	for level, zapLevel := range zapLevels {
+		if current <= zapLevel {
+			return level
 		}
-		value := fields[i+1]
-		logMsg = fmt.Sprintf("%s %s=%v", logMsg, key, value)
 	}
-
-	infoLogger.Println(logMsg)
+	return FATAL
 }
 
-// LogError 记录错误日志
-func LogError(msg string, fields ...interface{}) {
-	if len(fields)%2 != 0 {
-		errorLogger.Printf("Invalid number of fields for LogError: %d", len(fields))
-		return
+// LogLevelName 获取日志级别名称
+func LogLevelName(level int) string {
+	if level < DEBUG || level > FATAL {
+		return "unknown"
 	}
+	return logLevelNames[level]
+}
 
-	// 构建日志消息
-	logMsg := fmt.Sprintf("[%s] %s", time.Now().Format("2006-01-02 15:04:05"), msg)
-	fieldCount := len(fields)
-
-	for i := 0; i < fieldCount; i += 2 {
-		key, ok := fields[i].(string)
-		if !ok {
-			errorLogger.Printf("Invalid key type in LogError: %T", fields[i])
-			continue
+// ParseLogLevel 解析日志级别名称，不区分大小写
+func ParseLogLevel(name string) (int, error) {
+	for level, levelName := range logLevelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
 		}
-		value := fields[i+1]
-		logMsg = fmt.Sprintf("%s %s=%v", logMsg, key, value)
 	}
+	return 0, fmt.Errorf("未知的日志级别: %s", name)
+}
+
+// Debugf 打印调试日志
+func Debugf(format string, v ...interface{}) {
+	packageLogger.Load().Debugf(format, v...)
+}
+
+// Infof 打印信息日志
+func Infof(format string, v ...interface{}) {
+	packageLogger.Load().Infof(format, v...)
+}
+
+// Warnf 打印警告日志
+func Warnf(format string, v ...interface{}) {
+	packageLogger.Load().Warnf(format, v...)
+}
+
+// Errorf 打印错误日志
+func Errorf(format string, v ...interface{}) {
+	packageLogger.Load().Errorf(format, v...)
+}
+
+// Fatalf 打印致命错误日志并退出
+func Fatalf(format string, v ...interface{}) {
+	packageLogger.Load().Fatalf(format, v...)
+}
+
+// LogInfo 记录信息日志，fields 为键值对
+func LogInfo(msg string, fields ...interface{}) {
+	packageLogger.Load().Info(msg, fields...)
+}
 
-	errorLogger.Println(logMsg)
+// LogError 记录错误日志，fields 为键值对
+func LogError(msg string, fields ...interface{}) {
+	packageLogger.Load().Error(msg, fields...)
 }
 
 // 添加路径清理函数
@@ -229,10 +336,3 @@ func isPathSafe(path, baseDir string) bool {
 	cleanPath := filepath.Clean(path)
 	return strings.HasPrefix(cleanPath, cleanBase)
 }
-
-// NewLogger 创建新的日志记录器
-func NewLogger(config *LogConfig) *Logger {
-	return &Logger{
-		config: config,
-	}
-}
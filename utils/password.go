@@ -10,6 +10,12 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// 密码强度校验错误
+var (
+	ErrPasswordTooShort = errors.New("password must be at least 8 characters long")
+	ErrPasswordTooWeak  = errors.New("password must contain at least one number, one uppercase letter, one lowercase letter, and one special character")
+)
+
 // HashPassword 对密码进行加密
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -40,7 +46,7 @@ func GenerateRandomPassword() (string, error) {
 // 密码必须同时包含大小写字母、数字和特殊字符，且长度至少为8位
 func ValidatePasswordStrength(password string) error {
 	if len(password) < 8 {
-		return errors.New("password must be at least 8 characters long")
+		return ErrPasswordTooShort
 	}
 
 	hasNumber := regexp.MustCompile(`\d`).MatchString(password)
@@ -49,7 +55,7 @@ func ValidatePasswordStrength(password string) error {
 	hasSpecialChar := regexp.MustCompile(`[!@#$%^&*()_+\-=\[\]{};':"\\|,.<>/?]`).MatchString(password)
 
 	if !hasNumber || !hasUpperCase || !hasLowerCase || !hasSpecialChar {
-		return errors.New("password must contain at least one number, one uppercase letter, one lowercase letter, and one special character")
+		return ErrPasswordTooWeak
 	}
 
 	return nil
@@ -1,9 +1,11 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
+	"gitee.com/NextEraAbyss/gin-template/internal/redact"
 	"github.com/gin-gonic/gin"
 )
 
@@ -84,7 +86,7 @@ func WrapAppResponseError(err error, code ErrorCode, message string) *AppRespons
 	return &AppResponseError{
 		Code:     code,
 		Message:  message,
-		Details:  err.Error(),
+		Details:  redact.String(err.Error()),
 		HTTPCode: GetHTTPStatusCode(code),
 		Err:      err,
 	}
@@ -101,13 +103,18 @@ func (e *AppResponseError) ToResponse() Response {
 
 // 系统级状态码
 const (
-	CodeSuccess       ErrorCode = 0    // 成功
-	CodeInvalidParams ErrorCode = 1001 // 无效的参数
-	CodeUnauthorized  ErrorCode = 1002 // 未授权
-	CodeForbidden     ErrorCode = 1003 // 禁止访问
-	CodeNotFound      ErrorCode = 1004 // 资源不存在
-	CodeInternalError ErrorCode = 1005 // 内部错误
-	CodeServerError   ErrorCode = 1006 // 服务器错误
+	CodeSuccess             ErrorCode = 0    // 成功
+	CodeInvalidParams       ErrorCode = 1001 // 无效的参数
+	CodeUnauthorized        ErrorCode = 1002 // 未授权
+	CodeForbidden           ErrorCode = 1003 // 禁止访问
+	CodeNotFound            ErrorCode = 1004 // 资源不存在
+	CodeInternalError       ErrorCode = 1005 // 内部错误
+	CodeServerError         ErrorCode = 1006 // 服务器错误
+	CodeTooManyRequests     ErrorCode = 1007 // 请求过于频繁
+	CodeRequestInProgress   ErrorCode = 1008 // 相同幂等键的请求正在处理
+	CodeIdempotencyMismatch ErrorCode = 1009 // 幂等键已用于其他请求
+	CodeRequestTooLarge     ErrorCode = 1010 // 请求内容过大
+	CodeRequestTimeout      ErrorCode = 1011 // 请求处理超时
 
 	// CodeUserNotFound 用户相关错误 (2000-2999)
 	CodeUserNotFound  ErrorCode = 2001 // 用户不存在
@@ -144,13 +151,18 @@ var (
 
 // CodeMessages 状态码对应的消息
 var CodeMessages = map[ErrorCode]string{
-	CodeSuccess:       "操作成功",
-	CodeUnauthorized:  "未授权",
-	CodeForbidden:     "禁止访问",
-	CodeNotFound:      "资源不存在",
-	CodeInvalidParams: "请求参数错误",
-	CodeInternalError: "服务器内部错误",
-	CodeServerError:   "服务器错误",
+	CodeSuccess:             "操作成功",
+	CodeUnauthorized:        "未授权",
+	CodeForbidden:           "禁止访问",
+	CodeNotFound:            "资源不存在",
+	CodeInvalidParams:       "请求参数错误",
+	CodeInternalError:       "服务器内部错误",
+	CodeServerError:         "服务器错误",
+	CodeTooManyRequests:     "请求过于频繁",
+	CodeRequestInProgress:   "相同幂等键的请求正在处理，请稍后重试",
+	CodeIdempotencyMismatch: "幂等键已用于其他请求",
+	CodeRequestTooLarge:     "请求内容过大",
+	CodeRequestTimeout:      "请求处理超时，请稍后重试",
 
 	// 用户相关错误消息
 	CodeUserNotFound:  "用户不存在",
@@ -178,6 +190,16 @@ func GetHTTPStatusCode(code ErrorCode) int {
 		return http.StatusForbidden
 	case CodeNotFound:
 		return http.StatusNotFound
+	case CodeTooManyRequests:
+		return http.StatusTooManyRequests
+	case CodeRequestInProgress:
+		return http.StatusConflict
+	case CodeIdempotencyMismatch:
+		return http.StatusUnprocessableEntity
+	case CodeRequestTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case CodeRequestTimeout:
+		return http.StatusGatewayTimeout
 	case CodeInternalError, CodeServerError:
 		return http.StatusInternalServerError
 	default:
@@ -213,15 +235,20 @@ func ResponseWithAppError(c *gin.Context, respError *AppResponseError) {
 }
 
 // ResponseError 错误响应 - 返回错误码和消息
+// 请求已超过处理时限时，服务端错误统一返回请求超时
 func ResponseError(c *gin.Context, code ErrorCode, message string) {
+	// 获取HTTP状态码
+	httpCode := GetHTTPStatusCode(code)
+	if httpCode >= http.StatusInternalServerError && c.Request != nil &&
+		errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+		code, message, httpCode = CodeRequestTimeout, "", GetHTTPStatusCode(CodeRequestTimeout)
+	}
+
 	// 如果没有提供自定义消息，使用默认错误消息
 	if message == "" {
 		message = CodeMessages[code]
 	}
 
-	// 获取HTTP状态码
-	httpCode := GetHTTPStatusCode(code)
-
 	c.JSON(httpCode, Response{
 		Code:    int(code),
 		Message: message,
@@ -244,11 +271,36 @@ func ResponseErrorWithData(c *gin.Context, code ErrorCode, message string, data
 	})
 }
 
+// responseErrorKey gin上下文中保存服务端错误的键
+const responseErrorKey = "response_error"
+
+// ResponseErrorFromContext 获取 LogAndResponseError 返回服务端错误响应时记录的原始错误，供错误上报使用
+func ResponseErrorFromContext(c *gin.Context) error {
+	if value, ok := c.Get(responseErrorKey); ok {
+		if err, ok := value.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
 // LogAndResponseError 记录错误并返回错误响应
+// 服务端错误只记录日志，响应使用默认错误消息，避免向客户端泄露数据库等内部错误；
+// 其他错误的消息经过脱敏后返回
 func LogAndResponseError(c *gin.Context, code ErrorCode, err error) {
 	if err != nil {
-		Errorf("Error: %v", err)
-		ResponseError(c, code, err.Error())
+		message := redact.String(err.Error())
+		FromContext(c.Request.Context()).Error("request error", "error", message)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ResponseError(c, CodeRequestTooLarge, "")
+			return
+		}
+		if GetHTTPStatusCode(code) >= http.StatusInternalServerError {
+			c.Set(responseErrorKey, err)
+			message = ""
+		}
+		ResponseError(c, code, message)
 		return
 	}
 	ResponseError(c, code, "")
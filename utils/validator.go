@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
 	"strings"
 
@@ -83,6 +84,16 @@ func BindAndValidate(c *gin.Context, obj interface{}) bool {
 
 // processValidationError 处理验证错误
 func processValidationError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		ResponseError(c, CodeRequestTooLarge, "")
+		return
+	}
+	ResponseError(c, CodeInvalidParams, TranslateValidationError(err))
+}
+
+// TranslateValidationError 将验证错误翻译为可读的错误信息
+func TranslateValidationError(err error) string {
 	var validationErrs validator.ValidationErrors
 	if errors.As(err, &validationErrs) {
 		// 翻译错误信息
@@ -90,11 +101,15 @@ func processValidationError(c *gin.Context, err error) {
 		for _, e := range validationErrs {
 			errs = append(errs, e.Translate(trans))
 		}
-		ResponseError(c, CodeInvalidParams, strings.Join(errs, ", "))
-		return
+		return strings.Join(errs, ", ")
 	}
 	// 处理JSON解析错误
-	ResponseError(c, CodeInvalidParams, fmt.Sprintf("参数解析错误: %s", err.Error()))
+	return fmt.Sprintf("参数解析错误: %s", err.Error())
+}
+
+// ValidateStruct 使用 binding 标签验证结构体，适用于非请求来源的数据（如导入文件中的记录）
+func ValidateStruct(obj interface{}) error {
+	return binding.Validator.ValidateStruct(obj)
 }
 
 // ValidateQuery 验证查询参数
@@ -0,0 +1,60 @@
+package validation
+
+import (
+	"encoding/json"
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/models"
+)
+
+// AuditLogQueryDTO 审计日志查询参数
+type AuditLogQueryDTO struct {
+	Page       int       `form:"page" binding:"omitempty,min=1"`
+	PageSize   int       `form:"page_size" binding:"omitempty,min=1,max=100"`
+	EntityType string    `form:"entity_type" binding:"omitempty,max=50"`
+	EntityID   string    `form:"entity_id" binding:"omitempty,max=64"`
+	ActorID    uint      `form:"actor_id" binding:"omitempty,min=1"`
+	Action     string    `form:"action" binding:"omitempty,oneof=create update delete"`
+	StartTime  time.Time `form:"start_time" time_format:"2006-01-02 15:04:05" time_location:"Local"`
+	EndTime    time.Time `form:"end_time" time_format:"2006-01-02 15:04:05" time_location:"Local"`
+}
+
+// AuditLogResponseDTO 审计日志响应
+type AuditLogResponseDTO struct {
+	ID         uint            `json:"id"`
+	ActorID    uint            `json:"actor_id"`
+	RequestID  string          `json:"request_id"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Changes    json.RawMessage `json:"changes" swaggertype:"object"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+// AuditLogListResponseDTO 审计日志列表响应
+type AuditLogListResponseDTO struct {
+	Total    int64                 `json:"total"`     // 总数
+	Items    []AuditLogResponseDTO `json:"items"`     // 审计日志列表
+	Page     int                   `json:"page"`      // 当前页码
+	PageSize int                   `json:"page_size"` // 每页数量
+	Pages    int                   `json:"pages"`     // 总页数
+}
+
+// FromAuditLog 从AuditLog模型创建AuditLogResponseDTO
+func FromAuditLog(log models.AuditLog) AuditLogResponseDTO {
+	changes := json.RawMessage(log.Changes)
+	if len(changes) == 0 {
+		changes = json.RawMessage("{}")
+	}
+
+	return AuditLogResponseDTO{
+		ID:         log.ID,
+		ActorID:    log.ActorID,
+		RequestID:  log.RequestID,
+		Action:     log.Action,
+		EntityType: log.EntityType,
+		EntityID:   log.EntityID,
+		Changes:    changes,
+		CreatedAt:  log.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
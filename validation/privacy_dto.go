@@ -0,0 +1,58 @@
+package validation
+
+import (
+	"time"
+
+	"gitee.com/NextEraAbyss/gin-template/models"
+)
+
+// PrivacyExportDownloadURL 数据导出文件下载地址
+const PrivacyExportDownloadURL = "/api/v1/users/me/export/download"
+
+// PrivacyExportQueryDTO 数据导出参数
+type PrivacyExportQueryDTO struct {
+	Format string `form:"format" binding:"omitempty,oneof=zip json"` // 导出格式，默认zip
+}
+
+// PrivacyRequestResponseDTO 隐私请求响应
+type PrivacyRequestResponseDTO struct {
+	ID          uint   `json:"id"`                     // 请求ID
+	Type        string `json:"type"`                   // 请求类型: export或erasure
+	Format      string `json:"format,omitempty"`       // 导出格式
+	Status      string `json:"status"`                 // 处理状态
+	ScheduledAt string `json:"scheduled_at"`           // 计划执行时间，删除请求为宽限期结束时间
+	CompletedAt string `json:"completed_at,omitempty"` // 完成时间
+	ExpiresAt   string `json:"expires_at,omitempty"`   // 导出文件过期时间
+	DownloadURL string `json:"download_url,omitempty"` // 导出文件下载地址
+	Error       string `json:"error,omitempty"`        // 失败原因
+	CreatedAt   string `json:"created_at"`             // 申请时间
+}
+
+// FromPrivacyRequest 从PrivacyRequest模型创建PrivacyRequestResponseDTO
+func FromPrivacyRequest(req models.PrivacyRequest) PrivacyRequestResponseDTO {
+	dto := PrivacyRequestResponseDTO{
+		ID:          req.ID,
+		Type:        req.Type,
+		Format:      req.Format,
+		Status:      req.Status,
+		ScheduledAt: req.ScheduledAt.Format("2006-01-02 15:04:05"),
+		CompletedAt: formatOptionalTime(req.CompletedAt),
+		ExpiresAt:   formatOptionalTime(req.ExpiresAt),
+		Error:       req.Error,
+		CreatedAt:   req.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if req.Type == models.PrivacyRequestExport && req.Status == models.PrivacyStatusCompleted {
+		dto.DownloadURL = PrivacyExportDownloadURL
+	}
+
+	return dto
+}
+
+// formatOptionalTime 格式化可为空的时间
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,38 @@
+package validation
+
+// 用户导入导出支持的文件格式
+const (
+	TransferFormatCSV    = "csv"
+	TransferFormatNDJSON = "ndjson"
+)
+
+// UserImportQueryDTO 用户导入参数
+type UserImportQueryDTO struct {
+	Format         string `form:"format" binding:"omitempty,oneof=csv ndjson"` // 文件格式，为空时根据文件扩展名判断
+	DryRun         bool   `form:"dry_run"`                                     // 仅校验不写入
+	UpdatePassword bool   `form:"update_password"`                             // 更新已有用户的密码，默认只更新邮箱、昵称和状态
+}
+
+// UserExportQueryDTO 用户导出参数
+type UserExportQueryDTO struct {
+	Format  string `form:"format" binding:"omitempty,oneof=csv ndjson"`
+	Keyword string `form:"keyword" binding:"omitempty,max=50"`
+	Status  int    `form:"status" binding:"omitempty,oneof=0 1 2"`
+}
+
+// UserImportErrorDTO 导入失败的记录
+type UserImportErrorDTO struct {
+	Row      int    `json:"row"`                // 记录序号，从1开始，不含CSV表头
+	Username string `json:"username,omitempty"` // 用户名
+	Message  string `json:"message"`            // 错误信息
+}
+
+// UserImportResultDTO 用户导入结果
+type UserImportResultDTO struct {
+	DryRun          bool                 `json:"dry_run"`          // 是否仅校验
+	Total           int                  `json:"total"`            // 记录总数
+	Succeeded       int                  `json:"succeeded"`        // 成功数（仅校验时为校验通过数）
+	Failed          int                  `json:"failed"`           // 失败数
+	Errors          []UserImportErrorDTO `json:"errors"`           // 失败记录明细
+	ErrorsTruncated bool                 `json:"errors_truncated"` // 失败记录过多时明细被截断
+}